@@ -23,6 +23,10 @@ import (
 // @host      localhost:8080
 // @BasePath  /v1
 
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+
 func main() {
 	const op = "main"
 	cfg := config.Load()
@@ -8,12 +8,14 @@ import (
 
 	"github.com/OxytocinGroup/theca-v3/internal/config"
 	"github.com/OxytocinGroup/theca-v3/internal/database"
+	"github.com/OxytocinGroup/theca-v3/internal/metrics"
 	"github.com/OxytocinGroup/theca-v3/internal/model"
 	"github.com/OxytocinGroup/theca-v3/internal/repository"
 	"github.com/OxytocinGroup/theca-v3/internal/server"
 	"github.com/OxytocinGroup/theca-v3/internal/server/handlers"
 	"github.com/OxytocinGroup/theca-v3/internal/server/middleware"
 	"github.com/OxytocinGroup/theca-v3/internal/service"
+	"github.com/OxytocinGroup/theca-v3/internal/worker"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerfiles "github.com/swaggo/files"
@@ -27,10 +29,15 @@ type Application struct {
 	log            *slog.Logger
 	server         *server.Server
 	authMiddleware middleware.AuthMiddleware
+	workers        []worker.Worker
 }
 
 func New(ctx context.Context, cfg *config.Config, log *slog.Logger) *Application {
-	server := server.New(cfg, log)
+	server, err := server.New(cfg, log)
+	if err != nil {
+		log.Error("failed to create server", "error", err)
+		os.Exit(1)
+	}
 
 	if cfg.IsLocalRun {
 		server.Router().Use(gin.Logger())
@@ -43,15 +50,33 @@ func New(ctx context.Context, cfg *config.Config, log *slog.Logger) *Application
 		log.Error("failed to connect to database", "error", err)
 		os.Exit(1)
 	}
-	if err := db.AutoMigrate(&model.User{}, &model.Bookmark{}); err != nil {
+	if err := db.AutoMigrate(&model.User{}, &model.Bookmark{}, &model.Link{}, &model.Click{}, &model.Domain{}, &model.Tag{}, &model.LinkRevision{}, &model.Campaign{}, &model.LinkRule{}, &model.LinkVariant{}, &model.VisitorSalt{}, &model.VisitorSketch{}); err != nil {
 		log.Error("failed to migrate database", "error", err)
 		os.Exit(1)
 	}
 
 	repo := repository.NewRepository(db.GetDB(), log)
-	service := service.NewService(repo, log, cfg)
+	clicks := worker.NewBatch("clickWriter", worker.BatchConfig{
+		Dropped:  metrics.RecordDroppedClicks,
+		Capacity: cfg.ClickBufferSize,
+		Size:     cfg.ClickBatchSize,
+		Workers:  cfg.ClickWriters,
+		Interval: cfg.ClickFlushInterval,
+		Wait:     cfg.ClickEnqueueWait,
+	}, repo.CreateClicks, log)
+	service, err := service.NewService(repo, log, cfg, service.WithClickQueue(clicks))
+	if err != nil {
+		log.Error("failed to create service", "error", err)
+		os.Exit(1)
+	}
+	// Clicks are hashed with salts cached by the rotator, whose first run
+	// is an interval away.
+	if err := service.RotateVisitorSalts(ctx); err != nil {
+		log.Error("failed to load visitor salts", "error", err)
+		os.Exit(1)
+	}
 
-	handlers := handlers.NewHandler(service, log)
+	handlers := handlers.NewHandler(service, log, cfg)
 
 	authMiddleware := middleware.NewAuthMiddleware(cfg.JWTAccessSecret, cfg.JWTRefreshSecret)
 
@@ -63,12 +88,34 @@ func New(ctx context.Context, cfg *config.Config, log *slog.Logger) *Application
 		log:            log,
 		server:         server,
 		authMiddleware: authMiddleware,
+		// The click writer stops first, so its final flush is not held up
+		// behind the periodic jobs.
+		workers: []worker.Worker{
+			clicks,
+			worker.NewPeriodic("linkReaper", cfg.LinkReaperInterval, service.ReapExpiredLinks, log),
+			worker.NewPeriodic("trashPurger", cfg.TrashPurgeInterval, service.PurgeTrash, log),
+			worker.NewPeriodic("domainClaimReaper", cfg.DomainReapInterval, service.ReapDomainClaims, log),
+			worker.NewPeriodic("metadataFetcher", cfg.MetadataInterval, service.FetchMetadata, log),
+			worker.NewPeriodic("healthChecker", cfg.HealthPollInterval, service.CheckHealth, log),
+			worker.NewPeriodic("visitorSaltRotator", cfg.SaltRotateInterval, service.RotateVisitorSalts, log),
+		},
 	}
 
 	return app
 }
 
 func initHandlers(server *server.Server, handlers *handlers.Handler, authMiddleware middleware.AuthMiddleware) {
+	// Short codes live at the root of the public router; static segments such
+	// as /v1 take precedence over the :code wildcard.
+	server.Router().GET("/", handlers.Root)
+	server.Router().GET("/robots.txt", handlers.RobotsTxt)
+	server.Router().GET("/apple-app-site-association", handlers.AppleAppSiteAssociation)
+	server.Router().GET("/.well-known/apple-app-site-association", handlers.AppleAppSiteAssociation)
+	server.Router().GET("/.well-known/assetlinks.json", handlers.AssetLinks)
+	server.Router().GET("/:code", handlers.Redirect)
+	server.Router().HEAD("/:code", handlers.Redirect)
+	server.Router().POST("/:code", handlers.UnlockLink)
+
 	v1 := server.Router().Group("/v1")
 	v1.POST("/register", handlers.Register)
 	v1.POST("/login", handlers.Login)
@@ -76,6 +123,61 @@ func initHandlers(server *server.Server, handlers *handlers.Handler, authMiddlew
 	sec := v1.Group("/api", authMiddleware.JWTMiddleware())
 	sec.DELETE("/logout", handlers.Logout)
 
+	links := sec.Group("/links")
+	links.POST("", handlers.CreateLink)
+	links.GET("", handlers.ListLinks)
+	links.POST("/bulk", handlers.BulkCreateLinks)
+	links.GET("/:id", handlers.GetLink)
+	links.PATCH("/:id", handlers.UpdateLink)
+	links.DELETE("/:id", handlers.DeleteLink)
+	links.GET("/:id/stats", handlers.LinkTimeSeries)
+	links.GET("/:id/visitors", handlers.LinkUniqueVisitors)
+	links.GET("/:id/referrers", handlers.LinkReferrers)
+	links.GET("/:id/agents", handlers.LinkAgents)
+	links.GET("/:id/variants/stats", handlers.VariantStats)
+	links.GET("/:id/qr", handlers.LinkQRCode)
+	links.GET("/:id/revisions", handlers.ListLinkRevisions)
+	links.POST("/:id/revisions/:rev/rollback", handlers.RollbackLink)
+
+	campaigns := sec.Group("/campaigns")
+	campaigns.POST("", handlers.CreateCampaign)
+	campaigns.GET("", handlers.ListCampaigns)
+	campaigns.GET("/:id", handlers.GetCampaign)
+	campaigns.PATCH("/:id", handlers.UpdateCampaign)
+	campaigns.DELETE("/:id", handlers.DeleteCampaign)
+	campaigns.GET("/:id/stats", handlers.CampaignStats)
+	campaigns.GET("/:id/referrers", handlers.CampaignReferrers)
+
+	tags := sec.Group("/tags")
+	tags.POST("", handlers.CreateTag)
+	tags.GET("", handlers.ListTags)
+	tags.PATCH("/:id", handlers.UpdateTag)
+	tags.DELETE("/:id", handlers.DeleteTag)
+
+	sec.GET("/stats", handlers.ClickSummary)
+	sec.GET("/stats/timeseries", handlers.ClickTimeSeries)
+	sec.GET("/stats/visitors", handlers.UniqueVisitors)
+	sec.GET("/stats/agents", handlers.AgentBreakdown)
+
+	bookmarks := sec.Group("/bookmarks")
+	bookmarks.POST("", handlers.CreateBookmark)
+	bookmarks.GET("", handlers.ListBookmarks)
+	bookmarks.DELETE("/:id", handlers.DeleteBookmark)
+
+	trash := sec.Group("/trash")
+	trash.GET("", handlers.ListTrash)
+	trash.POST("/links/:id/restore", handlers.RestoreLink)
+	trash.DELETE("/links/:id", handlers.PurgeLink)
+	trash.POST("/bookmarks/:id/restore", handlers.RestoreBookmark)
+	trash.DELETE("/bookmarks/:id", handlers.PurgeBookmark)
+
+	domains := sec.Group("/domains")
+	domains.POST("", handlers.CreateDomain)
+	domains.GET("", handlers.ListDomains)
+	domains.GET("/:id", handlers.GetDomain)
+	domains.PATCH("/:id", handlers.UpdateDomain)
+	domains.DELETE("/:id", handlers.DeleteDomain)
+	domains.POST("/:id/verify", handlers.VerifyDomain)
 }
 
 func initPrivateHandlers(server *server.Server) {
@@ -86,6 +188,9 @@ func initPrivateHandlers(server *server.Server) {
 func (a *Application) Run() {
 	const op = "app.Run"
 	a.server.Start()
+	for _, w := range a.workers {
+		w.Start()
+	}
 	log := a.log.With(slog.String("op", op))
 	log.Info("application started",
 		slog.String("timestamp", time.Now().Format(time.RFC3339)),
@@ -99,6 +204,9 @@ func (a *Application) Stop() {
 	log.Info("shutting down application...")
 
 	a.server.Stop()
+	for _, w := range a.workers {
+		w.Stop()
+	}
 
 	a.log.Info("application stopped")
 }
@@ -4,44 +4,132 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	PGSSLMode        string
-	SQLitePath       string
-	PGName           string
-	PGUser           string
-	PGPassword       string
-	PGDB             string
-	PublicAddr       string
-	SwaggerAddr      string
-	LogLevel         string
-	AppName          string
-	JWTRefreshSecret []byte
-	JWTAccessSecret  []byte
-	PGPort           int
-	IsLocalRun       bool
+	PGSSLMode          string
+	SQLitePath         string
+	PGName             string
+	PGUser             string
+	PGPassword         string
+	PGDB               string
+	PublicAddr         string
+	SwaggerAddr        string
+	LogLevel           string
+	AppName            string
+	CodeStrategy       string
+	CodeSalt           string
+	DefaultDomain      string
+	ShortLinkScheme    string
+	RootURL            string
+	DNSResolverAddr    string
+	GeoIPDatabase      string
+	UserAgentRules     string
+	JWTRefreshSecret   []byte
+	JWTAccessSecret    []byte
+	LinkUnlockSecret   []byte
+	ReservedCodes      []string
+	CORSOrigins        []string
+	ReferrerClasses    []string
+	TrustedProxies     []string
+	LinkReaperInterval time.Duration
+	LinkUnlockTTL      time.Duration
+	UnlockFailWindow   time.Duration
+	TrashRetention     time.Duration
+	VariantCookieTTL   time.Duration
+	TrashPurgeInterval time.Duration
+	DomainClaimTTL     time.Duration
+	DomainReapInterval time.Duration
+	FetchTimeout       time.Duration
+	MetadataInterval   time.Duration
+	HealthInterval     time.Duration
+	HealthPollInterval time.Duration
+	SaltRotateInterval time.Duration
+	ClickFlushInterval time.Duration
+	ClickEnqueueWait   time.Duration
+	MetadataMaxBytes   int64
+	PGPort             int
+	CodeLength         int
+	CodeMaxAttempts    int
+	AliasMinLength     int
+	AliasMaxLength     int
+	UnlockMaxFailures  int
+	UnlockLinkFailures int
+	BulkMaxRows        int
+	FetchHostLimit     int
+	HealthMaxFailures  int
+	ClickBufferSize    int
+	ClickBatchSize     int
+	ClickWriters       int
+	IsLocalRun         bool
 }
 
 func Load() *Config {
 	_ = godotenv.Load()
 	return &Config{
-		AppName:          "theca",
-		LogLevel:         getEnv("LOG_LEVEL", "INFO"),
-		PGName:           getEnv("PG_NAME", "postgres"),
-		PGUser:           getEnv("PG_USER", "postgres"),
-		PGPassword:       getEnv("PG_PASSWORD", "postgres"),
-		PGDB:             getEnv("PG_DB", "postgres"),
-		PGPort:           getInt("PG_PORT", 5432),
-		PGSSLMode:        getEnv("PG_SSL_MODE", "disable"),
-		IsLocalRun:       parseBool("IS_LOCAL_RUN"),
-		SQLitePath:       getEnv("SQLITE_PATH", "theca_local.db"),
-		PublicAddr:       getEnv("PUBLIC_ADDR", ":8080"),
-		JWTAccessSecret:  []byte(getEnv("JWT_ACCESS_SECRET", "default_access_secret")),
-		JWTRefreshSecret: []byte(getEnv("JWT_REFRESH_SECRET", "default_refresh_secret")),
-		SwaggerAddr:      getEnv("SWAGGER_ADDR", ":8081"),
+		AppName:            "theca",
+		LogLevel:           getEnv("LOG_LEVEL", "INFO"),
+		PGName:             getEnv("PG_NAME", "postgres"),
+		PGUser:             getEnv("PG_USER", "postgres"),
+		PGPassword:         getEnv("PG_PASSWORD", "postgres"),
+		PGDB:               getEnv("PG_DB", "postgres"),
+		PGPort:             getInt("PG_PORT", 5432),
+		PGSSLMode:          getEnv("PG_SSL_MODE", "disable"),
+		IsLocalRun:         parseBool("IS_LOCAL_RUN"),
+		SQLitePath:         getEnv("SQLITE_PATH", "theca_local.db"),
+		PublicAddr:         getEnv("PUBLIC_ADDR", ":8080"),
+		JWTAccessSecret:    []byte(getEnv("JWT_ACCESS_SECRET", "default_access_secret")),
+		JWTRefreshSecret:   []byte(getEnv("JWT_REFRESH_SECRET", "default_refresh_secret")),
+		SwaggerAddr:        getEnv("SWAGGER_ADDR", ":8081"),
+		DefaultDomain:      getEnv("DEFAULT_DOMAIN", "via.oxytocingroup.com"),
+		ShortLinkScheme:    getEnv("SHORT_LINK_SCHEME", "https"),
+		RootURL:            getEnv("ROOT_URL", ""),
+		DNSResolverAddr:    getEnv("DNS_RESOLVER_ADDR", ""),
+		GeoIPDatabase:      getEnv("GEOIP_DATABASE", ""),
+		UserAgentRules:     getEnv("USER_AGENT_RULES", ""),
+		CORSOrigins:        getList("CORS_ORIGINS", []string{"https://theca.oxytocingroup.com", "http://localhost:3000"}),
+		TrustedProxies:     getList("TRUSTED_PROXIES", nil),
+		CodeStrategy:       getEnv("CODE_STRATEGY", "random"),
+		CodeLength:         getInt("CODE_LENGTH", 7),
+		CodeMaxAttempts:    getInt("CODE_MAX_ATTEMPTS", 5),
+		CodeSalt:           getEnv("CODE_SALT", "via"),
+		AliasMinLength:     getInt("ALIAS_MIN_LENGTH", 3),
+		AliasMaxLength:     getInt("ALIAS_MAX_LENGTH", 64),
+		LinkReaperInterval: getDuration("LINK_REAPER_INTERVAL", time.Minute),
+		LinkUnlockSecret:   []byte(getEnv("LINK_UNLOCK_SECRET", "default_unlock_secret")),
+		LinkUnlockTTL:      getDuration("LINK_UNLOCK_TTL", 30*time.Minute),
+		UnlockMaxFailures:  getInt("UNLOCK_MAX_FAILURES", 5),
+		UnlockLinkFailures: getInt("UNLOCK_LINK_MAX_FAILURES", 100),
+		UnlockFailWindow:   getDuration("UNLOCK_FAIL_WINDOW", 15*time.Minute),
+		BulkMaxRows:        getInt("BULK_MAX_ROWS", 1000),
+		TrashRetention:     getDuration("TRASH_RETENTION", 30*24*time.Hour),
+		VariantCookieTTL:   getDuration("VARIANT_COOKIE_TTL", 30*24*time.Hour),
+		TrashPurgeInterval: getDuration("TRASH_PURGE_INTERVAL", time.Hour),
+		DomainClaimTTL:     getDuration("DOMAIN_CLAIM_TTL", 7*24*time.Hour),
+		DomainReapInterval: getDuration("DOMAIN_REAP_INTERVAL", time.Hour),
+		FetchTimeout:       getDuration("FETCH_TIMEOUT", 5*time.Second),
+		FetchHostLimit:     getInt("FETCH_HOST_LIMIT", 2),
+		MetadataInterval:   getDuration("METADATA_INTERVAL", 30*time.Second),
+		MetadataMaxBytes:   int64(getInt("METADATA_MAX_BYTES", 512*1024)),
+		HealthInterval:     getDuration("HEALTH_CHECK_INTERVAL", 6*time.Hour),
+		HealthPollInterval: getDuration("HEALTH_CHECK_POLL_INTERVAL", time.Minute),
+		HealthMaxFailures:  getInt("HEALTH_CHECK_MAX_FAILURES", 3),
+		SaltRotateInterval: getDuration("VISITOR_SALT_INTERVAL", time.Hour),
+		ClickBufferSize:    getInt("CLICK_BUFFER_SIZE", 10000),
+		ClickBatchSize:     getInt("CLICK_BATCH_SIZE", 500),
+		ClickWriters:       getInt("CLICK_WRITERS", 2),
+		ClickFlushInterval: getDuration("CLICK_FLUSH_INTERVAL", time.Second),
+		ClickEnqueueWait:   getDuration("CLICK_ENQUEUE_WAIT", 5*time.Millisecond),
+		ReferrerClasses:    getList("REFERRER_CLASSES", nil),
+		ReservedCodes: getList("RESERVED_CODES", []string{
+			"v1", "api", "swagger", "metrics", "admin", "static", "assets",
+			"health", "login", "register", "logout", "robots.txt", "favicon.ico",
+			"apple-app-site-association",
+		}),
 	}
 }
 
@@ -79,3 +167,31 @@ func getInt(key string, defaultValue int) int {
 	}
 	return intVal
 }
+
+func getList(key string, defaultValue []string) []string {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultValue
+	}
+
+	items := make([]string, 0)
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		return defaultValue
+	}
+	return d
+}
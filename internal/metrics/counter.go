@@ -10,6 +10,8 @@ type CountMetrics struct {
 	OutRequestCounter *prometheus.CounterVec
 	CircuitBreaker    *prometheus.CounterVec
 	StorageData       *prometheus.GaugeVec
+	BrokenTargets     *prometheus.GaugeVec
+	ClicksDropped     *prometheus.CounterVec
 	RequestsPerSecond *prometheus.GaugeVec
 	RequestDuration   *prometheus.HistogramVec
 }
@@ -48,12 +50,27 @@ func newMetricCounter() *CountMetrics {
 			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15), // from 1ms to ~16s
 		}, []string{"path", "method"})
 
+	brokenTargets := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "via",
+			Name:      "broken_targets",
+			Help:      "Active links and bookmarks whose destination is flagged as broken",
+		}, []string{"kind"})
+
+	clicksDropped := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "via",
+		Name:      "clicks_dropped_total",
+		Help:      "Click events given up on before reaching the database",
+	}, []string{"reason"})
+
 	prometheus.MustRegister(
 		requestCounter,
 		outRequestCounter,
 		errorTotalCounter,
 		requestsPerSecond,
 		requestDuration,
+		brokenTargets,
+		clicksDropped,
 	)
 
 	return &CountMetrics{
@@ -62,5 +79,7 @@ func newMetricCounter() *CountMetrics {
 		ErrorTotalCounter: errorTotalCounter,
 		RequestsPerSecond: requestsPerSecond,
 		RequestDuration:   requestDuration,
+		BrokenTargets:     brokenTargets,
+		ClicksDropped:     clicksDropped,
 	}
 }
@@ -9,6 +9,18 @@ func RecordError(ctx context.Context, errorName, path, method string) {
 	GetInstance().IncrementErrorCounter(errorName, path, method)
 }
 
+// SetBrokenTargets publishes the number of broken targets of a kind, such as
+// "link" or "bookmark".
+func SetBrokenTargets(kind string, count int64) {
+	GetInstance().SetBrokenTargets(kind, count)
+}
+
+// RecordDroppedClicks counts click events that were dropped for a reason,
+// such as a full buffer or a failed write.
+func RecordDroppedClicks(reason string, n int) {
+	GetInstance().AddDroppedClicks(reason, n)
+}
+
 func MeasureExecutionTime(path, method string, fn func()) {
 	start := time.Now()
 	fn()
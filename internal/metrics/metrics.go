@@ -58,3 +58,11 @@ func (m *Manager) RecordRequestDuration(path, method string, start time.Time) {
 	duration := time.Since(start).Seconds()
 	m.Counter.RequestDuration.WithLabelValues(path, method).Observe(duration)
 }
+
+func (m *Manager) SetBrokenTargets(kind string, count int64) {
+	m.Counter.BrokenTargets.WithLabelValues(kind).Set(float64(count))
+}
+
+func (m *Manager) AddDroppedClicks(reason string, n int) {
+	m.Counter.ClicksDropped.WithLabelValues(reason).Add(float64(n))
+}
@@ -1,5 +1,16 @@
 package model
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type User struct {
 	Email               string `json:"email" gorm:"size:255;unique;not null"`
 	Username            string `json:"username" gorm:"size:255;unique;not null"`
@@ -13,10 +24,430 @@ type User struct {
 }
 
 type Bookmark struct {
-	Title    string `json:"title" gorm:"size:128"`
-	URL      string `json:"url" gorm:"size:255"`
-	IconURL  string `json:"icon_url" gorm:"size:255"`
-	ID       uint   `json:"id" gorm:"primaryKey;not null;unique"`
-	UserID   uint   `json:"user_id"`
-	ShowText bool   `json:"show_text" gorm:"default:false"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index" swaggertype:"string"`
+	Title     string         `json:"title" gorm:"size:128"`
+	URL       string         `json:"url" gorm:"size:255"`
+	IconURL   string         `json:"icon_url" gorm:"size:255"`
+	ID        uint           `json:"id" gorm:"primaryKey;not null;unique"`
+	UserID    uint           `json:"user_id"`
+	ShowText  bool           `json:"show_text" gorm:"default:false"`
+	Health    Health         `json:"health" gorm:"embedded;embeddedPrefix:health_"`
+	// MetadataPending marks bookmarks whose empty title or icon is still to
+	// be fetched from the page.
+	MetadataPending bool `json:"-" gorm:"index;not null;default:false"`
+}
+
+type Link struct {
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	ExpiresAt         *time.Time     `json:"expires_at"`
+	ExpiredAt         *time.Time     `json:"expired_at" gorm:"index"`
+	DeletedAt         gorm.DeletedAt `json:"deleted_at" gorm:"index" swaggertype:"string"`
+	Code              string         `json:"code" gorm:"size:64;uniqueIndex:idx_links_domain_code;not null"`
+	Destination       string         `json:"destination" gorm:"size:2048;not null"`
+	FallbackURL       string         `json:"fallback_url" gorm:"size:2048"`
+	Title             string         `json:"title" gorm:"size:255"`
+	Description       string         `json:"description" gorm:"size:1024"`
+	IconURL           string         `json:"icon_url" gorm:"size:2048"`
+	PasswordHash      string         `json:"-" gorm:"size:255"`
+	UTM               UTM            `json:"utm" gorm:"embedded;embeddedPrefix:utm_"`
+	DeepLink          DeepLink       `json:"deep_link" gorm:"embedded;embeddedPrefix:deep_"`
+	Health            Health         `json:"health" gorm:"embedded;embeddedPrefix:health_"`
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	UserID            uint           `json:"user_id" gorm:"index;not null"`
+	DomainID          uint           `json:"domain_id" gorm:"uniqueIndex:idx_links_domain_code;not null;default:0"`
+	CampaignID        uint           `json:"campaign_id" gorm:"index;not null;default:0"`
+	MaxClicks         uint           `json:"max_clicks" gorm:"not null;default:0"`
+	ClickCount        int64          `json:"click_count" gorm:"<-:create;not null;default:0"`
+	RedirectType      int            `json:"redirect_type" gorm:"not null;default:302"`
+	UTMOverride       bool           `json:"utm_override" gorm:"not null;default:false"`
+	StickyVariants    bool           `json:"sticky_variants" gorm:"not null;default:false"`
+	Enabled           bool           `json:"enabled" gorm:"not null"`
+	MetadataPending   bool           `json:"-" gorm:"index;not null;default:false"`
+	PasswordProtected bool           `json:"password_protected" gorm:"-"`
+	Tags              []Tag          `json:"tags" gorm:"many2many:link_tags"`
+	Rules             []LinkRule     `json:"rules" gorm:"constraint:OnDelete:CASCADE"`
+	Variants          []LinkVariant  `json:"variants" gorm:"constraint:OnDelete:CASCADE"`
+	// Campaign is joined in when a link is resolved for a redirect, for its
+	// UTM presets. Links outside a campaign hold 0, so it has no foreign key.
+	Campaign *Campaign `json:"-" gorm:"foreignKey:CampaignID;-:migration"`
+}
+
+// AfterFind derives PasswordProtected, since API consumers never see the hash.
+func (l *Link) AfterFind(*gorm.DB) error {
+	l.PasswordProtected = l.PasswordHash != ""
+	return nil
+}
+
+func (l *Link) AfterSave(*gorm.DB) error {
+	l.PasswordProtected = l.PasswordHash != ""
+	return nil
+}
+
+const (
+	RevisionActionCreate   = "create"
+	RevisionActionUpdate   = "update"
+	RevisionActionRollback = "rollback"
+)
+
+// LinkRevision is an immutable record of a link's settings after a change.
+// Snapshot holds the full settings, Changes only the fields that differ from
+// the previous revision as {"field": {"from": ..., "to": ...}}.
+type LinkRevision struct {
+	CreatedAt    time.Time `json:"created_at"`
+	Snapshot     JSONText  `json:"snapshot" gorm:"type:text;not null"`
+	Changes      JSONText  `json:"changes" gorm:"type:text"`
+	Action       string    `json:"action" gorm:"size:16;not null"`
+	RestoredFrom *uint     `json:"restored_from"`
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	LinkID       uint      `json:"link_id" gorm:"uniqueIndex:idx_link_revisions_link_number;not null"`
+	Number       uint      `json:"number" gorm:"uniqueIndex:idx_link_revisions_link_number;not null"`
+	UserID       uint      `json:"user_id" gorm:"not null"`
+}
+
+// LinkSnapshot is the part of a link that is versioned by revisions.
+type LinkSnapshot struct {
+	ExpiresAt         *time.Time    `json:"expires_at"`
+	Code              string        `json:"code"`
+	Destination       string        `json:"destination"`
+	FallbackURL       string        `json:"fallback_url"`
+	Title             string        `json:"title"`
+	UTM               UTM           `json:"utm"`
+	DeepLink          DeepLink      `json:"deep_link"`
+	Tags              []string      `json:"tags"`
+	Rules             []LinkRule    `json:"rules"`
+	Variants          []LinkVariant `json:"variants"`
+	DomainID          uint          `json:"domain_id"`
+	CampaignID        uint          `json:"campaign_id"`
+	MaxClicks         uint          `json:"max_clicks"`
+	RedirectType      int           `json:"redirect_type"`
+	UTMOverride       bool          `json:"utm_override"`
+	StickyVariants    bool          `json:"sticky_variants"`
+	Enabled           bool          `json:"enabled"`
+	PasswordProtected bool          `json:"password_protected"`
+}
+
+// JSONText is a JSON document kept in a text column and emitted verbatim in
+// API responses.
+type JSONText string
+
+func (j JSONText) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
+
+func (j *JSONText) UnmarshalJSON(data []byte) error {
+	*j = JSONText(data)
+	return nil
+}
+
+// LinkRule sends visitors that match all of its non-empty conditions to its
+// own destination. Within a condition any listed value matches. Rules are
+// tried in order of position and the first match wins; visitors matching no
+// rule go to the link destination.
+type LinkRule struct {
+	Countries   StringList `json:"countries" gorm:"size:1024"`
+	Devices     StringList `json:"devices" gorm:"size:255"`
+	OS          StringList `json:"os" gorm:"size:255"`
+	Languages   StringList `json:"languages" gorm:"size:1024"`
+	Destination string     `json:"destination" gorm:"size:2048;not null"`
+	ID          uint       `json:"-" gorm:"primaryKey"`
+	LinkID      uint       `json:"-" gorm:"index;not null"`
+	Position    int        `json:"-" gorm:"not null"`
+}
+
+// Health is the outcome of the periodic checks of a destination. A target is
+// broken after a configured number of consecutive failed checks and healthy
+// again after the first successful one.
+type Health struct {
+	CheckedAt     *time.Time `json:"checked_at" gorm:"index"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	RedirectChain JSONText   `json:"redirect_chain" gorm:"type:text"`
+	Error         string     `json:"error" gorm:"size:255"`
+	StatusCode    int        `json:"status_code" gorm:"not null;default:0"`
+	LatencyMS     int64      `json:"latency_ms" gorm:"not null;default:0"`
+	Failures      int        `json:"consecutive_failures" gorm:"not null;default:0"`
+	Broken        bool       `json:"broken" gorm:"index;not null;default:false"`
+}
+
+// DeepLink opens the native app behind a link on iOS and Android. App URLs
+// are custom schemes, Android intent: URLs or https universal/app links.
+// Visitors without the app go to the store URL, or to the web destination
+// of the link when no store URL is set.
+type DeepLink struct {
+	IOSURL          string `json:"ios_url" gorm:"size:2048" binding:"max=2048"`
+	IOSStoreURL     string `json:"ios_store_url" gorm:"size:2048" binding:"omitempty,url,max=2048"`
+	AndroidURL      string `json:"android_url" gorm:"size:2048" binding:"max=2048"`
+	AndroidStoreURL string `json:"android_store_url" gorm:"size:2048" binding:"omitempty,url,max=2048"`
+}
+
+// LinkVariant is one destination of an A/B split. Each click picks a variant
+// with probability proportional to its weight. Variants are told apart by
+// label, which clicks record, so edits keep their statistics comparable.
+type LinkVariant struct {
+	Label       string `json:"label" gorm:"size:32;not null"`
+	Destination string `json:"destination" gorm:"size:2048;not null"`
+	ID          uint   `json:"-" gorm:"primaryKey"`
+	LinkID      uint   `json:"-" gorm:"index;not null"`
+	Weight      uint   `json:"weight" gorm:"not null"`
+	Position    int    `json:"-" gorm:"not null"`
+}
+
+// Visitor describes the request behind a redirect. Variant is the label of
+// the variant the visitor was assigned before, if any.
+type Visitor struct {
+	IP             net.IP
+	UserAgent      string
+	AcceptLanguage string
+	Variant        string
+}
+
+// RedirectTarget is where a visitor is sent and, for A/B split links, the
+// label of the variant that was chosen. When AppURL is set the visitor is
+// first offered the app and URL is only the fallback.
+type RedirectTarget struct {
+	URL     string
+	AppURL  string
+	Variant string
+}
+
+// QRCode is a rendered QR code image.
+type QRCode struct {
+	ContentType string
+	Data        []byte
+}
+
+// UTM holds UTM presets that are added to a destination at redirect time.
+type UTM struct {
+	Source   string `json:"source" gorm:"size:255" binding:"max=255"`
+	Medium   string `json:"medium" gorm:"size:255" binding:"max=255"`
+	Campaign string `json:"campaign" gorm:"size:255" binding:"max=255"`
+	Term     string `json:"term" gorm:"size:255" binding:"max=255"`
+	Content  string `json:"content" gorm:"size:255" binding:"max=255"`
+}
+
+// Campaign groups links. Its UTM presets apply to member links for every
+// parameter the link does not preset itself.
+type Campaign struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name" gorm:"size:128;uniqueIndex:idx_campaigns_user_name;not null"`
+	UTM       UTM       `json:"utm" gorm:"embedded;embeddedPrefix:utm_"`
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"uniqueIndex:idx_campaigns_user_name;not null"`
+}
+
+type Tag struct {
+	CreatedAt time.Time `json:"created_at"`
+	Name      string    `json:"name" gorm:"size:64;uniqueIndex:idx_tags_user_name;not null"`
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"uniqueIndex:idx_tags_user_name;not null"`
+	// LinkCount is only filled in by tag listings.
+	LinkCount int64 `json:"link_count" gorm:"->;-:migration"`
+}
+
+// ClickSummary aggregates the clicks of a set of links, such as the members
+// of a campaign. Sources counts clicks per source marker, with the empty
+// marker standing for clicks on the plain short URL.
+type ClickSummary struct {
+	Sources map[string]int64 `json:"sources"`
+	Links   []LinkClicks     `json:"links"`
+	Total   int64            `json:"total"`
+}
+
+// TimeSeries is a run of consecutive buckets from From to To, empty ones
+// included. Bucket starts are given in the requested time zone.
+type TimeSeries struct {
+	From     time.Time     `json:"from"`
+	To       time.Time     `json:"to"`
+	Interval string        `json:"interval"`
+	TZ       string        `json:"tz"`
+	Buckets  []ClickBucket `json:"buckets"`
+	Total    int64         `json:"total"`
+}
+
+type ClickBucket struct {
+	Start  time.Time `json:"start"`
+	Clicks int64     `json:"clicks"`
+}
+
+// LinkClicks is the click count of one link in a ClickSummary.
+type LinkClicks struct {
+	Code   string `json:"code"`
+	Title  string `json:"title"`
+	LinkID uint   `json:"link_id"`
+	Clicks int64  `json:"clicks"`
+}
+
+// ClickSourceQR marks clicks that came from scanning the QR code of a link,
+// whose encoded URL carries src=qr.
+const ClickSourceQR = "qr"
+
+// Click is one visit of a short link. IPHash identifies the visitor on the
+// day of the click without storing the address itself; see VisitorSalt.
+type Click struct {
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+	IPHash    string    `json:"-" gorm:"size:64"`
+	UserAgent string    `json:"user_agent" gorm:"size:512"`
+	Referrer  string    `json:"referrer" gorm:"size:2048"`
+	Host      string    `json:"host" gorm:"size:255"`
+	Variant   string    `json:"variant" gorm:"size:32"`
+	Source    string    `json:"source" gorm:"size:16"`
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	LinkID    uint      `json:"link_id" gorm:"index;not null"`
+	// ReferrerHost and ReferrerClass are where the click came from, by its
+	// utm_source or else its Referer header.
+	ReferrerHost  string `json:"referrer_host" gorm:"size:255"`
+	ReferrerClass string `json:"referrer_class" gorm:"size:16"`
+	// Browser, OS and Device classify the user agent of the visitor.
+	Browser        string `json:"browser" gorm:"size:32"`
+	BrowserVersion string `json:"browser_version" gorm:"size:32"`
+	OS             string `json:"os" gorm:"size:32"`
+	OSVersion      string `json:"os_version" gorm:"size:32"`
+	Device         string `json:"device" gorm:"size:16"`
+	// UTMSource and UTMMedium are the utm parameters of the short URL itself.
+	UTMSource string `json:"-" gorm:"-"`
+	UTMMedium string `json:"-" gorm:"-"`
+}
+
+// VisitorDayFormat formats the UTC days visitor salts and sketches are kept
+// for.
+const VisitorDayFormat = "2006-01-02"
+
+// VisitorSalt is the random salt visitor hashes are keyed with on a UTC day.
+// Only the current and next day's salts are kept, so hashes from earlier days
+// can no longer be tied to an address.
+type VisitorSalt struct {
+	Day  string `gorm:"primaryKey;size:10"`
+	Salt []byte `gorm:"not null"`
+}
+
+// VisitorSketch is the HyperLogLog sketch of the visitors of a link on a UTC
+// day, see package hll.
+type VisitorSketch struct {
+	Day    string `gorm:"primaryKey;size:10"`
+	Sketch []byte `gorm:"not null"`
+	LinkID uint   `gorm:"primaryKey"`
+}
+
+// UniqueVisitors is the estimated number of distinct visitors over the UTC
+// days from From to To, inclusive.
+type UniqueVisitors struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Visitors uint64 `json:"visitors"`
+}
+
+// ReferrerBreakdown splits clicks by where they came from. Classes counts
+// the clicks of every referrer class and Sources lists the sources with the
+// most clicks, direct clicks under an empty host. Clicks recorded before
+// referrers were classified count under an empty class.
+type ReferrerBreakdown struct {
+	Classes map[string]int64 `json:"classes"`
+	Sources []ReferrerClicks `json:"sources"`
+	Total   int64            `json:"total"`
+}
+
+// AgentBreakdown splits clicks by the browser, operating system and device
+// of the visitor. Browsers and OS list the families, or with versions the
+// family versions, with the most clicks. Clicks recorded before user agents
+// were classified count under empty names.
+type AgentBreakdown struct {
+	Devices  map[string]int64 `json:"devices"`
+	Browsers []AgentClicks    `json:"browsers"`
+	OS       []AgentClicks    `json:"os"`
+	Total    int64            `json:"total"`
+}
+
+type AgentClicks struct {
+	Family  string `json:"family"`
+	Version string `json:"version,omitempty"`
+	Clicks  int64  `json:"clicks"`
+}
+
+type ReferrerClicks struct {
+	Host   string `json:"host"`
+	Class  string `json:"class"`
+	Clicks int64  `json:"clicks"`
+}
+
+const (
+	// DomainChallengePrefix is prepended to the host to get the TXT record
+	// name checked during domain verification.
+	DomainChallengePrefix = "_via-challenge."
+	// DomainChallengeValuePrefix is prepended to the verification token to
+	// get the expected TXT record value.
+	DomainChallengeValuePrefix = "via-verification="
+)
+
+// Domain is a custom host owned by a user. Links with DomainID 0 belong to
+// the default domain.
+type Domain struct {
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	VerifiedAt        *time.Time `json:"verified_at"`
+	Host              string     `json:"host" gorm:"size:255;uniqueIndex:idx_domains_verified_host,where:verified_at IS NOT NULL;not null"`
+	VerificationToken string     `json:"-" gorm:"size:64;not null"`
+	RootURL           string     `json:"root_url" gorm:"size:2048"`
+	NotFoundURL       string     `json:"not_found_url" gorm:"size:2048"`
+	RobotsTxt         string     `json:"robots_txt" gorm:"type:text"`
+	AndroidPackage    string     `json:"android_package" gorm:"size:255"`
+	IOSAppIDs         StringList `json:"ios_app_ids" gorm:"size:1024"`
+	AndroidCertHashes StringList `json:"android_cert_fingerprints" gorm:"size:2048"`
+	ChallengeName     string     `json:"challenge_name" gorm:"-"`
+	ChallengeValue    string     `json:"challenge_value" gorm:"-"`
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	UserID            uint       `json:"user_id" gorm:"index;not null"`
+}
+
+func (d *Domain) Verified() bool {
+	return d.VerifiedAt != nil
+}
+
+// AfterFind fills in the DNS TXT record the owner has to publish.
+func (d *Domain) AfterFind(*gorm.DB) error {
+	d.ChallengeName = DomainChallengePrefix + d.Host
+	d.ChallengeValue = DomainChallengeValuePrefix + d.VerificationToken
+	return nil
+}
+
+func (d *Domain) AfterSave(tx *gorm.DB) error {
+	return d.AfterFind(tx)
+}
+
+// StringList is a list of simple values, such as country codes, kept in a
+// single comma-separated text column.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+func (l *StringList) Scan(value any) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("unsupported StringList value %T", value)
+	}
+
+	*l = nil
+	if s != "" {
+		*l = strings.Split(s, ",")
+	}
+	return nil
+}
+
+// MarshalJSON emits an empty list rather than null.
+func (l StringList) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(l))
 }
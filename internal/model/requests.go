@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Username string `json:"username" binding:"required,min=3"`
@@ -31,3 +33,237 @@ type ResetPassword struct {
 type RequestVerificationToken struct {
 	Username string `json:"username" binding:"required,min=3"`
 }
+
+// CreateLinkRequest describes a new link. UTM presets are merged into the
+// destination query at redirect time; with utm_override they replace
+// parameters the destination already carries.
+type CreateLinkRequest struct {
+	ExpiresAt      *time.Time           `json:"expires_at"`
+	Enabled        *bool                `json:"enabled"`
+	Tags           []string             `json:"tags" binding:"max=20,dive,min=1,max=64"`
+	Destination    string               `json:"destination" binding:"required,url,max=2048"`
+	Alias          string               `json:"alias"`
+	Title          string               `json:"title" binding:"max=255"`
+	FallbackURL    string               `json:"fallback_url" binding:"omitempty,url,max=2048"`
+	Password       string               `json:"password" binding:"omitempty,min=4,max=72"`
+	UTM            UTM                  `json:"utm"`
+	DeepLink       DeepLink             `json:"deep_link"`
+	Rules          []LinkRuleRequest    `json:"rules" binding:"max=20,dive"`
+	Variants       []LinkVariantRequest `json:"variants" binding:"max=10,dive"`
+	MaxClicks      uint                 `json:"max_clicks"`
+	DomainID       uint                 `json:"domain_id"`
+	CampaignID     uint                 `json:"campaign_id"`
+	RedirectType   int                  `json:"redirect_type" binding:"omitempty,oneof=301 302 307 308"`
+	UTMOverride    bool                 `json:"utm_override"`
+	StickyVariants bool                 `json:"sticky_variants"`
+}
+
+// LinkRuleRequest describes a targeting rule. Countries are ISO 3166-1
+// alpha-2 codes and languages BCP 47 tags; a language without a region
+// matches all of its regional variants.
+type LinkRuleRequest struct {
+	Countries   []string `json:"countries" binding:"max=50,dive,len=2,alpha"`
+	Devices     []string `json:"devices" binding:"dive,oneof=desktop mobile tablet bot unknown"`
+	OS          []string `json:"os" binding:"dive,oneof=android ios windows macos linux chromeos other"`
+	Languages   []string `json:"languages" binding:"max=20,dive,min=2,max=35"`
+	Destination string   `json:"destination" binding:"required,url,max=2048"`
+}
+
+// LinkVariantRequest describes one destination of an A/B split. Labels
+// default to A, B, C... in order.
+type LinkVariantRequest struct {
+	Label       string `json:"label" binding:"omitempty,max=32,alphanum"`
+	Destination string `json:"destination" binding:"required,url,max=2048"`
+	Weight      uint   `json:"weight" binding:"required,min=1,max=10000"`
+}
+
+// BulkLinkRow is one row of a bulk link request. Err carries the error found
+// while decoding or validating the row, if any.
+type BulkLinkRow struct {
+	Err     error
+	Request CreateLinkRequest
+}
+
+// UpdateLinkRequest changes only the fields that are present. An empty
+// password removes the protection, a zero campaign_id detaches the link from
+// its campaign, and clear_expiration drops expires_at because a JSON null
+// cannot be told apart from an absent field. A present utm or deep_link
+// object, tags, rules or variants list replaces the whole set on the link.
+type UpdateLinkRequest struct {
+	ExpiresAt       *time.Time            `json:"expires_at"`
+	Destination     *string               `json:"destination" binding:"omitempty,url,max=2048"`
+	Title           *string               `json:"title" binding:"omitempty,max=255"`
+	FallbackURL     *string               `json:"fallback_url" binding:"omitempty,max=2048"`
+	Password        *string               `json:"password" binding:"omitempty,max=72"`
+	UTM             *UTM                  `json:"utm"`
+	DeepLink        *DeepLink             `json:"deep_link"`
+	Tags            *[]string             `json:"tags" binding:"omitempty,max=20,dive,min=1,max=64"`
+	Rules           *[]LinkRuleRequest    `json:"rules" binding:"omitempty,max=20,dive"`
+	Variants        *[]LinkVariantRequest `json:"variants" binding:"omitempty,max=10,dive"`
+	MaxClicks       *uint                 `json:"max_clicks"`
+	CampaignID      *uint                 `json:"campaign_id"`
+	RedirectType    *int                  `json:"redirect_type" binding:"omitempty,oneof=301 302 307 308"`
+	UTMOverride     *bool                 `json:"utm_override"`
+	StickyVariants  *bool                 `json:"sticky_variants"`
+	Enabled         *bool                 `json:"enabled"`
+	ClearExpiration bool                  `json:"clear_expiration"`
+}
+
+const (
+	SortCreated = "created"
+	SortUpdated = "updated"
+	SortClicks  = "clicks"
+	SortTitle   = "title"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+// PageRequest selects a page of a listing. Cursor is the next_cursor of the
+// previous page and is only valid with the sort and order it was issued
+// for. Q is a case-insensitive substring searched for in the title, the
+// destination and the short code.
+type PageRequest struct {
+	Cursor string `form:"cursor" binding:"max=1024"`
+	Sort   string `form:"sort" binding:"omitempty,oneof=created updated clicks title"`
+	Order  string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Q      string `form:"q" binding:"max=255"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// LinkFilter narrows link listings and analytics. Links must carry every
+// given tag. Broken selects links whose destination failed its recent health
+// checks, or with false the ones that did not.
+type LinkFilter struct {
+	CampaignID *uint    `form:"campaign_id"`
+	Broken     *bool    `form:"broken"`
+	Tags       []string `form:"tag" binding:"max=20,dive,max=64"`
+}
+
+// StatsFilter selects the clicks analytics are computed over: those on links
+// matching the link filter, made within [from, to). LinkID, set by per-link
+// endpoints, narrows them to a single link.
+type StatsFilter struct {
+	From   *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	LinkID uint       `form:"-"`
+	LinkFilter
+}
+
+// VisitorsRequest selects the UTC days unique visitors are counted over,
+// from and to inclusive; the last 30 days by default.
+type VisitorsRequest struct {
+	From   *time.Time `form:"from" time_format:"2006-01-02"`
+	To     *time.Time `form:"to" time_format:"2006-01-02"`
+	LinkID uint       `form:"-"`
+	LinkFilter
+}
+
+const (
+	IntervalHour  = "hour"
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// MaxTimeSeriesBuckets bounds the buckets of a single time series.
+const MaxTimeSeriesBuckets = 2000
+
+// TimeSeriesRequest asks for click counts bucketed by interval, day and
+// longer buckets starting at midnight in the TZ time zone and weeks on
+// Monday. Without a range the series covers the last 24 hours, 30 days,
+// 12 weeks or 12 months, by interval.
+type TimeSeriesRequest struct {
+	Interval string `form:"interval" binding:"omitempty,oneof=hour day week month"`
+	TZ       string `form:"tz" binding:"max=64"`
+	StatsFilter
+}
+
+// DefaultReferrerLimit is how many sources a referrer breakdown lists by
+// default.
+const DefaultReferrerLimit = 10
+
+// ReferrersRequest asks for the Limit sources with the most clicks.
+type ReferrersRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+	StatsFilter
+}
+
+// DefaultAgentLimit is how many browsers and operating systems an agent
+// breakdown lists by default.
+const DefaultAgentLimit = 10
+
+// AgentsRequest asks for the Limit browsers and operating systems with the
+// most clicks, split by version when Versions is set.
+type AgentsRequest struct {
+	Versions bool `form:"versions"`
+	Limit    int  `form:"limit" binding:"omitempty,min=1,max=100"`
+	StatsFilter
+}
+
+type CreateTagRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+type UpdateTagRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+// QRCodeRequest describes how the QR code of a link is rendered. Colours are
+// RRGGBB or RRGGBBAA hex values without the leading #. The logo is fetched
+// from its URL and only drawn on PNG images.
+type QRCodeRequest struct {
+	Margin     *int   `form:"margin" binding:"omitempty,min=0,max=16"`
+	Format     string `form:"format" binding:"omitempty,oneof=png svg"`
+	Level      string `form:"level" binding:"omitempty,oneof=L M Q H l m q h"`
+	Foreground string `form:"fg" binding:"omitempty,hexadecimal"`
+	Background string `form:"bg" binding:"omitempty,hexadecimal"`
+	Logo       string `form:"logo" binding:"omitempty,url,max=2048"`
+	Size       int    `form:"size" binding:"omitempty,min=64,max=4096"`
+}
+
+type CreateBookmarkRequest struct {
+	Title    string `json:"title" binding:"max=128"`
+	URL      string `json:"url" binding:"required,url,max=255"`
+	IconURL  string `json:"icon_url" binding:"omitempty,url,max=255"`
+	ShowText bool   `json:"show_text"`
+}
+
+type CreateCampaignRequest struct {
+	Name string `json:"name" binding:"required,max=128"`
+	UTM  UTM    `json:"utm"`
+}
+
+// UpdateCampaignRequest changes only the fields that are present; a present
+// utm object replaces all presets of the campaign.
+type UpdateCampaignRequest struct {
+	Name *string `json:"name" binding:"omitempty,min=1,max=128"`
+	UTM  *UTM    `json:"utm"`
+}
+
+// CreateDomainRequest adds a custom domain. iOS app IDs (TEAMID.bundle.id)
+// and the Android package with its SHA-256 signing certificate fingerprints
+// are published as apple-app-site-association and assetlinks.json once the
+// domain is verified.
+type CreateDomainRequest struct {
+	Host              string   `json:"host" binding:"required,hostname,max=255"`
+	RootURL           string   `json:"root_url" binding:"omitempty,url,max=2048"`
+	NotFoundURL       string   `json:"not_found_url" binding:"omitempty,url,max=2048"`
+	RobotsTxt         string   `json:"robots_txt" binding:"max=8192"`
+	AndroidPackage    string   `json:"android_package" binding:"max=255"`
+	IOSAppIDs         []string `json:"ios_app_ids" binding:"max=10"`
+	AndroidCertHashes []string `json:"android_cert_fingerprints" binding:"max=10"`
+}
+
+// UpdateDomainRequest changes only the fields that are present; empty
+// strings restore the default behavior.
+type UpdateDomainRequest struct {
+	RootURL           *string   `json:"root_url" binding:"omitempty,max=2048"`
+	NotFoundURL       *string   `json:"not_found_url" binding:"omitempty,max=2048"`
+	RobotsTxt         *string   `json:"robots_txt" binding:"omitempty,max=8192"`
+	AndroidPackage    *string   `json:"android_package" binding:"omitempty,max=255"`
+	IOSAppIDs         *[]string `json:"ios_app_ids" binding:"omitempty,max=10"`
+	AndroidCertHashes *[]string `json:"android_cert_fingerprints" binding:"omitempty,max=10"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+
+// BulkLinkResult reports the outcome of one row of a bulk link request.
+type BulkLinkResult struct {
+	Error *errors.APIError `json:"error,omitempty"`
+	Code  string           `json:"code,omitempty"`
+	Row   int              `json:"row"`
+	ID    uint             `json:"id,omitempty"`
+}
+
+type BulkLinksResponse struct {
+	Results   []BulkLinkResult `json:"results"`
+	Created   int              `json:"created"`
+	Failed    int              `json:"failed"`
+	Committed bool             `json:"committed"`
+}
+
+// TrashResponse lists soft-deleted items that can still be restored. Items
+// are purged for good once PurgeAfter has elapsed since their deletion.
+type TrashResponse struct {
+	Links      []Link     `json:"links"`
+	Bookmarks  []Bookmark `json:"bookmarks"`
+	PurgeAfter string     `json:"purge_after"`
+}
+
+// VariantStats compares the variants of an A/B split link. Clicks served by
+// variants that no longer exist are reported under their old label.
+type VariantStats struct {
+	Label       string `json:"label"`
+	Destination string `json:"destination,omitempty"`
+	Weight      uint   `json:"weight"`
+	Clicks      int64  `json:"clicks"`
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"gorm.io/gorm"
+)
+
+func (r *repository) CreateBookmark(ctx context.Context, bookmark *model.Bookmark) error {
+	const op = "repository.CreateBookmark"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Model(&model.Bookmark{}).Create(bookmark).Error
+	if err != nil {
+		log.Error("failed to create bookmark", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetUserBookmark(ctx context.Context, userID, id uint) (*model.Bookmark, error) {
+	const op = "repository.GetUserBookmark"
+	log := r.log.With("op", op)
+
+	var bookmark model.Bookmark
+	err := r.db.WithContext(ctx).Model(&model.Bookmark{}).Where("id = ? AND user_id = ?", id, userID).First(&bookmark).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customerrors.New(customerrors.CodeDataNotFound, "Закладка не найдена")
+		}
+		log.Error("failed to get bookmark", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return &bookmark, nil
+}
+
+// Bookmarks carry no timestamps, so they are listed by id for creation
+// order and cannot be sorted by update time or clicks.
+var bookmarkListing = listing[model.Bookmark]{
+	id:    "bookmarks.id",
+	rowID: func(b *model.Bookmark) uint { return b.ID },
+	keys: map[string]sortKey[model.Bookmark]{
+		model.SortCreated: intKey("bookmarks.id", orderDesc, func(b *model.Bookmark) int64 { return int64(b.ID) }),
+		model.SortTitle:   stringKey("bookmarks.title", orderAsc, func(b *model.Bookmark) string { return b.Title }),
+	},
+}
+
+// ListUserBookmarks returns a page of the user's bookmarks and the cursor of
+// the next page.
+func (r *repository) ListUserBookmarks(ctx context.Context, userID uint, page model.PageRequest) ([]model.Bookmark, string, error) {
+	const op = "repository.ListUserBookmarks"
+	log := r.log.With("op", op)
+
+	q, err := bookmarkListing.query(page)
+	if err != nil {
+		return nil, "", err
+	}
+
+	bookmarks := make([]model.Bookmark, 0)
+	err = r.db.WithContext(ctx).Model(&model.Bookmark{}).
+		Where("bookmarks.user_id = ?", userID).
+		Scopes(searchBookmarks(page.Q), q.scope).
+		Find(&bookmarks).Error
+	if err != nil {
+		log.Error("failed to list bookmarks", "error", err)
+		return nil, "", customerrors.FromGormError(err)
+	}
+
+	bookmarks, next := q.page(bookmarks)
+	return bookmarks, next, nil
+}
+
+// searchBookmarks selects bookmarks whose title or URL contains q.
+func searchBookmarks(q string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if q == "" {
+			return db
+		}
+		pattern := likePattern(q)
+		return db.Where(`(LOWER(bookmarks.title) LIKE ? ESCAPE '\' OR LOWER(bookmarks.url) LIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+}
+
+func (r *repository) DeleteBookmark(ctx context.Context, bookmark *model.Bookmark) error {
+	const op = "repository.DeleteBookmark"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Delete(bookmark).Error
+	if err != nil {
+		log.Error("failed to delete bookmark", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
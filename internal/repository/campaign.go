@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"gorm.io/gorm"
+)
+
+func (r *repository) CreateCampaign(ctx context.Context, campaign *model.Campaign) error {
+	const op = "repository.CreateCampaign"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Model(&model.Campaign{}).Create(campaign).Error
+	if err != nil {
+		log.Error("failed to create campaign", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetUserCampaign(ctx context.Context, userID, id uint) (*model.Campaign, error) {
+	const op = "repository.GetUserCampaign"
+	log := r.log.With("op", op)
+
+	var campaign model.Campaign
+	err := r.db.WithContext(ctx).Model(&model.Campaign{}).Where("id = ? AND user_id = ?", id, userID).First(&campaign).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customerrors.New(customerrors.CodeDataNotFound, "Кампания не найдена")
+		}
+		log.Error("failed to get campaign", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return &campaign, nil
+}
+
+func (r *repository) ListUserCampaigns(ctx context.Context, userID uint) ([]model.Campaign, error) {
+	const op = "repository.ListUserCampaigns"
+	log := r.log.With("op", op)
+
+	campaigns := make([]model.Campaign, 0)
+	err := r.db.WithContext(ctx).Model(&model.Campaign{}).Where("user_id = ?", userID).Order("name").Find(&campaigns).Error
+	if err != nil {
+		log.Error("failed to list campaigns", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return campaigns, nil
+}
+
+func (r *repository) SaveCampaign(ctx context.Context, campaign *model.Campaign) error {
+	const op = "repository.SaveCampaign"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Save(campaign).Error
+	if err != nil {
+		log.Error("failed to save campaign", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+// DeleteCampaign removes a campaign and detaches its links, trashed ones
+// included, so they stop picking up its presets.
+func (r *repository) DeleteCampaign(ctx context.Context, campaign *model.Campaign) error {
+	const op = "repository.DeleteCampaign"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&model.Link{}).Where("campaign_id = ?", campaign.ID).Update("campaign_id", 0).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(campaign).Error
+	})
+	if err != nil {
+		log.Error("failed to delete campaign", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
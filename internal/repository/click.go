@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"gorm.io/gorm"
+)
+
+// CreateClicks inserts a batch of clicks and adds them to the click counts
+// and visitor sketches of their links.
+func (r *repository) CreateClicks(ctx context.Context, clicks []model.Click) error {
+	const op = "repository.CreateClicks"
+	log := r.log.With("op", op)
+
+	counts := make(map[uint]int64)
+	for _, click := range clicks {
+		counts[click.LinkID]++
+	}
+	// Concurrent batches update the counters in the same order so they
+	// cannot deadlock on each other.
+	ids := slices.Sorted(maps.Keys(counts))
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Click{}).Create(&clicks).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			err := tx.Exec("UPDATE links SET click_count = click_count + ? WHERE id = ?", counts[id], id).Error
+			if err != nil {
+				return err
+			}
+		}
+		return mergeVisitorSketches(tx, clicks)
+	})
+	if err != nil {
+		log.Error("failed to create clicks", "count", len(clicks), "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+// SummarizeClicks aggregates the clicks on the user's links matching the
+// filter, trashed links excluded.
+func (r *repository) SummarizeClicks(ctx context.Context, userID uint, filter model.StatsFilter) (*model.ClickSummary, error) {
+	const op = "repository.SummarizeClicks"
+	log := r.log.With("op", op)
+
+	clicks := func() *gorm.DB {
+		return r.db.WithContext(ctx).Model(&model.Click{}).Scopes(filterClicks(userID, filter))
+	}
+
+	var sources []struct {
+		Source string
+		Clicks int64
+	}
+	err := clicks().Select("clicks.source, COUNT(*) AS clicks").Group("clicks.source").Scan(&sources).Error
+	if err != nil {
+		log.Error("failed to count clicks by source", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	summary := model.ClickSummary{
+		Sources: make(map[string]int64, len(sources)),
+		Links:   make([]model.LinkClicks, 0),
+	}
+	for _, row := range sources {
+		summary.Sources[row.Source] = row.Clicks
+		summary.Total += row.Clicks
+	}
+
+	err = clicks().
+		Select("links.id AS link_id, links.code, links.title, COUNT(*) AS clicks").
+		Group("links.id, links.code, links.title").
+		Order("clicks DESC, links.id").
+		Scan(&summary.Links).Error
+	if err != nil {
+		log.Error("failed to count clicks by link", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return &summary, nil
+}
+
+// CountClicksByReferrer breaks the clicks matching the filter down by
+// referrer class and lists the limit referrer hosts with the most clicks.
+func (r *repository) CountClicksByReferrer(ctx context.Context, userID uint, filter model.StatsFilter, limit int) (*model.ReferrerBreakdown, error) {
+	const op = "repository.CountClicksByReferrer"
+	log := r.log.With("op", op)
+
+	clicks := func() *gorm.DB {
+		return r.db.WithContext(ctx).Model(&model.Click{}).Scopes(filterClicks(userID, filter))
+	}
+
+	var classes []struct {
+		Class  string
+		Clicks int64
+	}
+	err := clicks().Select("clicks.referrer_class AS class, COUNT(*) AS clicks").Group("clicks.referrer_class").Scan(&classes).Error
+	if err != nil {
+		log.Error("failed to count clicks by referrer class", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	breakdown := model.ReferrerBreakdown{
+		Classes: make(map[string]int64, len(classes)),
+		Sources: make([]model.ReferrerClicks, 0),
+	}
+	for _, row := range classes {
+		breakdown.Classes[row.Class] = row.Clicks
+		breakdown.Total += row.Clicks
+	}
+
+	err = clicks().
+		Select("clicks.referrer_host AS host, clicks.referrer_class AS class, COUNT(*) AS clicks").
+		Group("clicks.referrer_host, clicks.referrer_class").
+		Order("clicks DESC, host, class").
+		Limit(limit).
+		Scan(&breakdown.Sources).Error
+	if err != nil {
+		log.Error("failed to count clicks by referrer host", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return &breakdown, nil
+}
+
+// CountClicksByAgent breaks the clicks matching the filter down by device
+// and lists the limit browsers and operating systems with the most clicks,
+// split by version if asked to.
+func (r *repository) CountClicksByAgent(ctx context.Context, userID uint, filter model.StatsFilter, versions bool, limit int) (*model.AgentBreakdown, error) {
+	const op = "repository.CountClicksByAgent"
+	log := r.log.With("op", op)
+
+	clicks := func() *gorm.DB {
+		return r.db.WithContext(ctx).Model(&model.Click{}).Scopes(filterClicks(userID, filter))
+	}
+
+	var devices []struct {
+		Device string
+		Clicks int64
+	}
+	err := clicks().Select("clicks.device AS device, COUNT(*) AS clicks").Group("clicks.device").Scan(&devices).Error
+	if err != nil {
+		log.Error("failed to count clicks by device", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	breakdown := model.AgentBreakdown{Devices: make(map[string]int64, len(devices))}
+	for _, row := range devices {
+		breakdown.Devices[row.Device] = row.Clicks
+		breakdown.Total += row.Clicks
+	}
+
+	families := func(family, version string) ([]model.AgentClicks, error) {
+		columns := "clicks." + family
+		selected := columns + " AS family"
+		if versions {
+			columns += ", clicks." + version
+			selected += ", clicks." + version + " AS version"
+		}
+		rows := make([]model.AgentClicks, 0)
+		err := clicks().
+			Select(selected + ", COUNT(*) AS clicks").
+			Group(columns).
+			Order("clicks DESC, " + columns).
+			Limit(limit).
+			Scan(&rows).Error
+		return rows, err
+	}
+
+	if breakdown.Browsers, err = families("browser", "browser_version"); err != nil {
+		log.Error("failed to count clicks by browser", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+	if breakdown.OS, err = families("os", "os_version"); err != nil {
+		log.Error("failed to count clicks by os", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return &breakdown, nil
+}
+
+// CountClicksBySlot counts the clicks matching the filter per slot of the
+// given length, keyed by the Unix time the slot starts at. Slots are aligned
+// to the Unix epoch.
+func (r *repository) CountClicksBySlot(ctx context.Context, userID uint, filter model.StatsFilter, slot time.Duration) (map[int64]int64, error) {
+	const op = "repository.CountClicksBySlot"
+	log := r.log.With("op", op)
+
+	epoch := "CAST(strftime('%s', clicks.created_at) AS INTEGER)"
+	if r.db.Dialector.Name() == "postgres" {
+		epoch = "CAST(FLOOR(EXTRACT(EPOCH FROM clicks.created_at)) AS BIGINT)"
+	}
+	seconds := int64(slot / time.Second)
+
+	var rows []struct {
+		Slot   int64
+		Clicks int64
+	}
+	err := r.db.WithContext(ctx).Model(&model.Click{}).
+		Scopes(filterClicks(userID, filter)).
+		Select(fmt.Sprintf("%[1]s / %[2]d * %[2]d AS slot, COUNT(*) AS clicks", epoch, seconds)).
+		Group("slot").
+		Scan(&rows).Error
+	if err != nil {
+		log.Error("failed to count clicks by slot", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	counts := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Slot] = row.Clicks
+	}
+	return counts, nil
+}
+
+// filterClicks selects the clicks on the user's links matching the filter,
+// trashed links excluded. Range bounds are compared in UTC, which clicks are
+// stored in.
+func filterClicks(userID uint, filter model.StatsFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Joins("JOIN links ON links.id = clicks.link_id AND links.deleted_at IS NULL").
+			Scopes(filterLinks(userID, filter.LinkFilter))
+		if filter.LinkID != 0 {
+			db = db.Where("clicks.link_id = ?", filter.LinkID)
+		}
+		if filter.From != nil {
+			db = db.Where("clicks.created_at >= ?", filter.From.UTC())
+		}
+		if filter.To != nil {
+			db = db.Where("clicks.created_at < ?", filter.To.UTC())
+		}
+		return db
+	}
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"gorm.io/gorm"
+)
+
+func (r *repository) CreateDomain(ctx context.Context, domain *model.Domain) error {
+	const op = "repository.CreateDomain"
+	log := r.log.With("op", op)
+
+	// A host may be claimed by several users until one of them proves
+	// control over it; only a verified domain or the user's own claim
+	// blocks a new one.
+	var c int64
+	err := r.db.WithContext(ctx).Model(&model.Domain{}).
+		Where("host = ? AND (verified_at IS NOT NULL OR user_id = ?)", domain.Host, domain.UserID).Count(&c).Error
+	if err != nil {
+		log.Error("failed to count domains", "error", err)
+		return customerrors.FromGormError(err)
+	}
+	if c > 0 {
+		return customerrors.New(customerrors.CodeDataConflict, "Домен уже добавлен")
+	}
+
+	err = r.db.WithContext(ctx).Model(&model.Domain{}).Create(domain).Error
+	if err != nil {
+		log.Error("failed to create domain", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetUserDomain(ctx context.Context, userID, id uint) (*model.Domain, error) {
+	const op = "repository.GetUserDomain"
+	log := r.log.With("op", op)
+
+	var domain model.Domain
+	err := r.db.WithContext(ctx).Model(&model.Domain{}).Where("id = ? AND user_id = ?", id, userID).First(&domain).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customerrors.New(customerrors.CodeDataNotFound, "Домен не найден")
+		}
+		log.Error("failed to get domain", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return &domain, nil
+}
+
+// GetDomainByHost returns the verified domain of a host.
+func (r *repository) GetDomainByHost(ctx context.Context, host string) (*model.Domain, error) {
+	const op = "repository.GetDomainByHost"
+	log := r.log.With("op", op)
+
+	var domain model.Domain
+	err := r.db.WithContext(ctx).Model(&model.Domain{}).Where("host = ? AND verified_at IS NOT NULL", host).First(&domain).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customerrors.New(customerrors.CodeDataNotFound, "Домен не найден")
+		}
+		log.Error("failed to get domain by host", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return &domain, nil
+}
+
+func (r *repository) ListUserDomains(ctx context.Context, userID uint) ([]model.Domain, error) {
+	const op = "repository.ListUserDomains"
+	log := r.log.With("op", op)
+
+	domains := make([]model.Domain, 0)
+	err := r.db.WithContext(ctx).Model(&model.Domain{}).Where("user_id = ?", userID).Order("id").Find(&domains).Error
+	if err != nil {
+		log.Error("failed to list domains", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return domains, nil
+}
+
+func (r *repository) SaveDomain(ctx context.Context, domain *model.Domain) error {
+	const op = "repository.SaveDomain"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Save(domain).Error
+	if err != nil {
+		log.Error("failed to save domain", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+// VerifyDomain saves a domain that has just been verified. The host moves
+// to it from whichever domain held it before, since the DNS challenge proves
+// the new owner controls the host now.
+func (r *repository) VerifyDomain(ctx context.Context, domain *model.Domain) error {
+	const op = "repository.VerifyDomain"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Domain{}).
+			Where("host = ? AND id <> ? AND verified_at IS NOT NULL", domain.Host, domain.ID).
+			Update("verified_at", nil)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			log.Info("domain taken over", "host", domain.Host, "domain", domain.ID)
+		}
+		return tx.Save(domain).Error
+	})
+	if err != nil {
+		log.Error("failed to verify domain", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+// DeleteStaleDomainClaims deletes unverified domains untouched since the
+// given time, keeping those that still have links from before they lost
+// their host.
+func (r *repository) DeleteStaleDomainClaims(ctx context.Context, before time.Time) (int64, error) {
+	const op = "repository.DeleteStaleDomainClaims"
+	log := r.log.With("op", op)
+
+	res := r.db.WithContext(ctx).
+		Where("verified_at IS NULL AND updated_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM links WHERE links.domain_id = domains.id)").
+		Delete(&model.Domain{})
+	if res.Error != nil {
+		log.Error("failed to delete stale domain claims", "error", res.Error)
+		return 0, customerrors.FromGormError(res.Error)
+	}
+
+	return res.RowsAffected, nil
+}
+
+func (r *repository) DeleteDomain(ctx context.Context, domain *model.Domain) error {
+	const op = "repository.DeleteDomain"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Delete(domain).Error
+	if err != nil {
+		log.Error("failed to delete domain", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+// CountDomainLinks counts the links on a domain, including trashed ones that
+// could still be restored onto it.
+func (r *repository) CountDomainLinks(ctx context.Context, domainID uint) (int64, error) {
+	const op = "repository.CountDomainLinks"
+	log := r.log.With("op", op)
+
+	var c int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.Link{}).Where("domain_id = ?", domainID).Count(&c).Error
+	if err != nil {
+		log.Error("failed to count domain links", "error", err)
+		return 0, customerrors.FromGormError(err)
+	}
+
+	return c, nil
+}
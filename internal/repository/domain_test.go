@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+)
+
+func TestCreateDomainClaims(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+
+	first := &model.Domain{Host: "go.example.org", UserID: 1, VerificationToken: "a"}
+	if err := repo.CreateDomain(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateDomain(ctx, &model.Domain{Host: "go.example.org", UserID: 1, VerificationToken: "b"}); !customerrors.IsErrorCode(err, customerrors.CodeDataConflict) {
+		t.Fatalf("second claim of the same user: got %v, want a conflict", err)
+	}
+	if err := repo.CreateDomain(ctx, &model.Domain{Host: "go.example.org", UserID: 2, VerificationToken: "c"}); err != nil {
+		t.Fatalf("an unverified claim blocked another user: %v", err)
+	}
+
+	now := time.Now()
+	first.VerifiedAt = &now
+	if err := repo.VerifyDomain(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateDomain(ctx, &model.Domain{Host: "go.example.org", UserID: 3, VerificationToken: "d"}); !customerrors.IsErrorCode(err, customerrors.CodeDataConflict) {
+		t.Fatalf("claim of a verified host: got %v, want a conflict", err)
+	}
+}
+
+func TestVerifyDomainTakesOverHost(t *testing.T) {
+	repo, db := newTestRepository(t)
+	ctx := context.Background()
+
+	old := &model.Domain{Host: "go.example.org", UserID: 1, VerificationToken: "a"}
+	claim := &model.Domain{Host: "go.example.org", UserID: 2, VerificationToken: "b"}
+	for _, d := range []*model.Domain{old, claim} {
+		if err := repo.CreateDomain(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	old.VerifiedAt = &now
+	if err := repo.VerifyDomain(ctx, old); err != nil {
+		t.Fatal(err)
+	}
+	claim.VerifiedAt = &now
+	if err := repo.VerifyDomain(ctx, claim); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.GetDomainByHost(ctx, "go.example.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != claim.ID {
+		t.Fatalf("host resolves to domain %d, want %d", got.ID, claim.ID)
+	}
+	var previous model.Domain
+	if err := db.First(&previous, old.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if previous.Verified() {
+		t.Fatal("the previous owner is still verified")
+	}
+
+	// The partial index holds even when the takeover is skipped.
+	previous.VerifiedAt = &now
+	if err := db.Save(&previous).Error; err == nil {
+		t.Fatal("two verified domains share a host")
+	}
+}
+
+func TestDeleteStaleDomainClaims(t *testing.T) {
+	repo, db := newTestRepository(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	stale := now.Add(-48 * time.Hour)
+	// gorm keeps an UpdatedAt that is given on create.
+	domains := map[string]*model.Domain{
+		"stale":    {Host: "stale.example.org", UserID: 1, UpdatedAt: stale},
+		"fresh":    {Host: "fresh.example.org", UserID: 1},
+		"verified": {Host: "verified.example.org", UserID: 1, UpdatedAt: stale, VerifiedAt: &stale},
+		"linked":   {Host: "linked.example.org", UserID: 1, UpdatedAt: stale},
+	}
+	for _, d := range domains {
+		if err := db.Create(d).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := model.Link{Code: "abc", Destination: "https://example.com/", UserID: 1, DomainID: domains["linked"].ID}
+	if err := db.Create(&link).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := repo.DeleteStaleDomainClaims(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Fatalf("deleted %d claims, want 1", deleted)
+	}
+	for name, d := range domains {
+		err := db.First(&model.Domain{}, d.ID).Error
+		if gone := err != nil; gone != (name == "stale") {
+			t.Errorf("%s domain gone: %v", name, gone)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"gorm.io/gorm"
+)
+
+// dueForHealthCheck selects rows never checked or last checked before the
+// given time, never-checked ones first.
+func dueForHealthCheck(before time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("health_checked_at IS NULL OR health_checked_at < ?", before).
+			Order("health_checked_at IS NOT NULL, health_checked_at, id")
+	}
+}
+
+// activeLinks selects links that currently redirect: enabled and not expired.
+// Trashed links are excluded by the default scope.
+func activeLinks(db *gorm.DB) *gorm.DB {
+	return db.Where("enabled = ? AND expired_at IS NULL", true)
+}
+
+// ListDueHealthLinks returns up to limit active links whose destination is
+// due for a health check.
+func (r *repository) ListDueHealthLinks(ctx context.Context, before time.Time, limit int) ([]model.Link, error) {
+	const op = "repository.ListDueHealthLinks"
+	log := r.log.With("op", op)
+
+	links := make([]model.Link, 0)
+	err := r.db.WithContext(ctx).Model(&model.Link{}).
+		Scopes(activeLinks, dueForHealthCheck(before)).
+		Limit(limit).
+		Find(&links).Error
+	if err != nil {
+		log.Error("failed to list links due for health check", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return links, nil
+}
+
+func (r *repository) UpdateLinkHealth(ctx context.Context, id uint, health model.Health) error {
+	const op = "repository.UpdateLinkHealth"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Model(&model.Link{}).Where("id = ?", id).UpdateColumns(healthColumns(health)).Error
+	if err != nil {
+		log.Error("failed to update link health", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+// CountBrokenLinks counts the active links flagged as broken.
+func (r *repository) CountBrokenLinks(ctx context.Context) (int64, error) {
+	const op = "repository.CountBrokenLinks"
+	log := r.log.With("op", op)
+
+	var c int64
+	err := r.db.WithContext(ctx).Model(&model.Link{}).Scopes(activeLinks).Where("health_broken = ?", true).Count(&c).Error
+	if err != nil {
+		log.Error("failed to count broken links", "error", err)
+		return 0, customerrors.FromGormError(err)
+	}
+
+	return c, nil
+}
+
+// ListDueHealthBookmarks returns up to limit bookmarks whose URL is due for a
+// health check.
+func (r *repository) ListDueHealthBookmarks(ctx context.Context, before time.Time, limit int) ([]model.Bookmark, error) {
+	const op = "repository.ListDueHealthBookmarks"
+	log := r.log.With("op", op)
+
+	bookmarks := make([]model.Bookmark, 0)
+	err := r.db.WithContext(ctx).Model(&model.Bookmark{}).
+		Scopes(dueForHealthCheck(before)).
+		Limit(limit).
+		Find(&bookmarks).Error
+	if err != nil {
+		log.Error("failed to list bookmarks due for health check", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return bookmarks, nil
+}
+
+func (r *repository) UpdateBookmarkHealth(ctx context.Context, id uint, health model.Health) error {
+	const op = "repository.UpdateBookmarkHealth"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Model(&model.Bookmark{}).Where("id = ?", id).UpdateColumns(healthColumns(health)).Error
+	if err != nil {
+		log.Error("failed to update bookmark health", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+func (r *repository) CountBrokenBookmarks(ctx context.Context) (int64, error) {
+	const op = "repository.CountBrokenBookmarks"
+	log := r.log.With("op", op)
+
+	var c int64
+	err := r.db.WithContext(ctx).Model(&model.Bookmark{}).Where("health_broken = ?", true).Count(&c).Error
+	if err != nil {
+		log.Error("failed to count broken bookmarks", "error", err)
+		return 0, customerrors.FromGormError(err)
+	}
+
+	return c, nil
+}
+
+// healthColumns lists every health column, so zero values such as a cleared
+// error or broken flag are written too.
+func healthColumns(h model.Health) map[string]any {
+	return map[string]any{
+		"health_checked_at":      h.CheckedAt,
+		"health_last_success_at": h.LastSuccessAt,
+		"health_redirect_chain":  h.RedirectChain,
+		"health_error":           h.Error,
+		"health_status_code":     h.StatusCode,
+		"health_latency_ms":      h.LatencyMS,
+		"health_failures":        h.Failures,
+		"health_broken":          h.Broken,
+	}
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"gorm.io/gorm"
+)
+
+func (r *repository) CreateLink(ctx context.Context, link *model.Link) error {
+	const op = "repository.CreateLink"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Model(&model.Link{}).Create(link).Error
+	if err != nil {
+		log.Error("failed to create link", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetUserLink(ctx context.Context, userID, id uint) (*model.Link, error) {
+	const op = "repository.GetUserLink"
+	log := r.log.With("op", op)
+
+	var link model.Link
+	err := r.db.WithContext(ctx).Model(&model.Link{}).Preload("Tags").Preload("Rules", byPosition).Preload("Variants", byPosition).Where("id = ? AND user_id = ?", id, userID).First(&link).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customerrors.New(customerrors.CodeDataNotFound, "Ссылка не найдена")
+		}
+		log.Error("failed to get link", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return &link, nil
+}
+
+func (r *repository) GetLinkByCode(ctx context.Context, domainID uint, code string) (*model.Link, error) {
+	const op = "repository.GetLinkByCode"
+	log := r.log.With("op", op)
+
+	var link model.Link
+	err := r.db.WithContext(ctx).Model(&model.Link{}).Joins("Campaign").Preload("Rules", byPosition).Preload("Variants", byPosition).Where("links.domain_id = ? AND links.code = ?", domainID, code).First(&link).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customerrors.New(customerrors.CodeDataNotFound, "Ссылка не найдена")
+		}
+		log.Error("failed to get link by code", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return &link, nil
+}
+
+var linkListing = listing[model.Link]{
+	id:    "links.id",
+	rowID: func(l *model.Link) uint { return l.ID },
+	keys: map[string]sortKey[model.Link]{
+		// Ids grow with creation time and, unlike timestamps, never tie.
+		model.SortCreated: intKey("links.id", orderDesc, func(l *model.Link) int64 { return int64(l.ID) }),
+		model.SortUpdated: timeKey("links.updated_at", orderDesc, func(l *model.Link) time.Time { return l.UpdatedAt }),
+		model.SortClicks:  intKey("links.click_count", orderDesc, func(l *model.Link) int64 { return l.ClickCount }),
+		model.SortTitle:   stringKey("links.title", orderAsc, func(l *model.Link) string { return l.Title }),
+	},
+}
+
+// ListUserLinks returns a page of the user's links matching the filter and
+// the cursor of the next page.
+func (r *repository) ListUserLinks(ctx context.Context, userID uint, filter model.LinkFilter, page model.PageRequest) ([]model.Link, string, error) {
+	const op = "repository.ListUserLinks"
+	log := r.log.With("op", op)
+
+	q, err := linkListing.query(page)
+	if err != nil {
+		return nil, "", err
+	}
+
+	links := make([]model.Link, 0)
+	err = r.db.WithContext(ctx).Model(&model.Link{}).
+		Preload("Tags").Preload("Rules", byPosition).Preload("Variants", byPosition).
+		Scopes(filterLinks(userID, filter), searchLinks(page.Q), q.scope).
+		Find(&links).Error
+	if err != nil {
+		log.Error("failed to list links", "error", err)
+		return nil, "", customerrors.FromGormError(err)
+	}
+
+	links, next := q.page(links)
+	return links, next, nil
+}
+
+// filterLinks selects the user's links matching filter. Columns are
+// qualified so the scope can be combined with joins.
+func filterLinks(userID uint, filter model.LinkFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("links.user_id = ?", userID)
+		if filter.CampaignID != nil {
+			db = db.Where("links.campaign_id = ?", *filter.CampaignID)
+		}
+		if filter.Broken != nil {
+			db = db.Where("links.health_broken = ?", *filter.Broken)
+		}
+		if len(filter.Tags) > 0 {
+			tagged := db.Session(&gorm.Session{NewDB: true}).Table("link_tags").
+				Select("link_tags.link_id").
+				Joins("JOIN tags ON tags.id = link_tags.tag_id").
+				Where("tags.user_id = ? AND tags.name IN ?", userID, filter.Tags).
+				Group("link_tags.link_id").
+				Having("COUNT(DISTINCT tags.id) = ?", len(filter.Tags))
+			db = db.Where("links.id IN (?)", tagged)
+		}
+		return db
+	}
+}
+
+// searchLinks selects links whose title, destination or code contains q.
+func searchLinks(q string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if q == "" {
+			return db
+		}
+		pattern := likePattern(q)
+		return db.Where(`(LOWER(links.title) LIKE ? ESCAPE '\' OR LOWER(links.destination) LIKE ? ESCAPE '\' OR LOWER(links.code) LIKE ? ESCAPE '\')`, pattern, pattern, pattern)
+	}
+}
+
+func (r *repository) LinkCodeExists(ctx context.Context, domainID uint, code string) (bool, error) {
+	const op = "repository.LinkCodeExists"
+	log := r.log.With("op", op)
+
+	// Trashed links keep their code until they are purged, so they can be
+	// restored without clashing with a newer link.
+	var c int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.Link{}).Where("domain_id = ? AND code = ?", domainID, code).Count(&c).Error
+	if err != nil {
+		log.Error("failed to count links", "error", err)
+		return false, customerrors.FromGormError(err)
+	}
+
+	return c > 0, nil
+}
+
+// TakenLinkCodes returns which of codes are held on the domain, trashed
+// links included as in LinkCodeExists.
+func (r *repository) TakenLinkCodes(ctx context.Context, domainID uint, codes []string) ([]string, error) {
+	const op = "repository.TakenLinkCodes"
+	log := r.log.With("op", op)
+
+	var taken []string
+	if len(codes) == 0 {
+		return taken, nil
+	}
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.Link{}).Where("domain_id = ? AND code IN ?", domainID, codes).Pluck("code", &taken).Error
+	if err != nil {
+		log.Error("failed to find taken codes", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return taken, nil
+}
+
+func (r *repository) SaveLink(ctx context.Context, link *model.Link) error {
+	const op = "repository.SaveLink"
+	log := r.log.With("op", op)
+
+	// Rules and variants are positional and replaced as a whole by
+	// ReplaceLinkRules and ReplaceLinkVariants.
+	err := r.db.WithContext(ctx).Omit("Rules", "Variants").Save(link).Error
+	if err != nil {
+		log.Error("failed to save link", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteLink(ctx context.Context, link *model.Link) error {
+	const op = "repository.DeleteLink"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Delete(link).Error
+	if err != nil {
+		log.Error("failed to delete link", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+// MarkExpiredLinks stamps expired_at on links that passed their expiration
+// date or used up their click budget, returning how many were marked.
+func (r *repository) MarkExpiredLinks(ctx context.Context, now time.Time) (int64, error) {
+	const op = "repository.MarkExpiredLinks"
+	log := r.log.With("op", op)
+
+	now = now.UTC()
+	res := r.db.WithContext(ctx).Model(&model.Link{}).
+		Where("expired_at IS NULL").
+		Where(r.db.Where("expires_at <= ?", now).
+			Or("max_clicks > 0 AND click_count >= max_clicks")).
+		Update("expired_at", now)
+	if res.Error != nil {
+		log.Error("failed to mark expired links", "error", res.Error)
+		return 0, customerrors.FromGormError(res.Error)
+	}
+
+	return res.RowsAffected, nil
+}
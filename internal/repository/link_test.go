@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"gorm.io/gorm"
+)
+
+func TestMarkExpiredLinksAcrossOffsets(t *testing.T) {
+	repo, db := newTestRepository(t)
+	ctx := context.Background()
+
+	// The reaper may run in any zone; expirations are stored in UTC.
+	east := time.FixedZone("UTC+5", 5*60*60)
+	now := time.Now().In(east)
+	future, past := now.Add(time.Hour).UTC(), now.Add(-time.Hour).UTC()
+
+	links := []model.Link{
+		{Code: "future", Destination: "https://example.com/", UserID: 1, ExpiresAt: &future},
+		{Code: "past", Destination: "https://example.com/", UserID: 1, ExpiresAt: &past},
+	}
+	if err := db.Create(&links).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	marked, err := repo.MarkExpiredLinks(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if marked != 1 {
+		t.Fatalf("marked %d links, want 1", marked)
+	}
+
+	var expired []string
+	if err := db.Model(&model.Link{}).Where("expired_at IS NOT NULL").Pluck("code", &expired).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 || expired[0] != "past" {
+		t.Fatalf("links %v were marked expired, want only past", expired)
+	}
+}
+
+// A link is resolved together with its campaign, so redirects need no query
+// of their own for the campaign's UTM presets.
+func TestGetLinkByCodeJoinsCampaign(t *testing.T) {
+	repo, db := newTestRepository(t)
+	ctx := context.Background()
+
+	campaign := model.Campaign{Name: "spring", UserID: 1, UTM: model.UTM{Source: "newsletter"}}
+	if err := db.Create(&campaign).Error; err != nil {
+		t.Fatal(err)
+	}
+	links := []model.Link{
+		{Code: "in", Destination: "https://example.com/", UserID: 1, CampaignID: campaign.ID, Enabled: true},
+		{Code: "out", Destination: "https://example.com/", UserID: 1, Enabled: true},
+	}
+	if err := db.Create(&links).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var queries int
+	if err := db.Callback().Query().Before("gorm:query").Register("count", func(*gorm.DB) { queries++ }); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := repo.GetLinkByCode(ctx, 0, "in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Campaign == nil || link.Campaign.UTM.Source != "newsletter" {
+		t.Fatalf("got campaign %+v, want spring", link.Campaign)
+	}
+	// The link with its campaign, then its rules and variants.
+	if queries != 3 {
+		t.Fatalf("resolving the link took %d queries, want 3", queries)
+	}
+
+	link, err = repo.GetLinkByCode(ctx, 0, "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Campaign != nil {
+		t.Fatalf("got campaign %+v for a link outside campaigns", link.Campaign)
+	}
+}
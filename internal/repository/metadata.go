@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"gorm.io/gorm"
+)
+
+// ListPendingMetadataLinks returns up to limit links waiting for their
+// destination metadata, oldest first.
+func (r *repository) ListPendingMetadataLinks(ctx context.Context, limit int) ([]model.Link, error) {
+	const op = "repository.ListPendingMetadataLinks"
+	log := r.log.With("op", op)
+
+	links := make([]model.Link, 0)
+	err := r.db.WithContext(ctx).Model(&model.Link{}).
+		Select("id", "destination").
+		Where("metadata_pending = ?", true).
+		Order("id").Limit(limit).
+		Find(&links).Error
+	if err != nil {
+		log.Error("failed to list links pending metadata", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return links, nil
+}
+
+// UpdateLinkMetadata stores fetched metadata and clears the pending flag. The
+// title is only filled in if it is still empty, so a title the user set in
+// the meantime wins.
+func (r *repository) UpdateLinkMetadata(ctx context.Context, id uint, title, description, iconURL string) error {
+	const op = "repository.UpdateLinkMetadata"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Model(&model.Link{}).Where("id = ?", id).UpdateColumns(map[string]any{
+		"title":            gorm.Expr("CASE WHEN title = '' THEN ? ELSE title END", title),
+		"description":      description,
+		"icon_url":         iconURL,
+		"metadata_pending": false,
+	}).Error
+	if err != nil {
+		log.Error("failed to update link metadata", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+// ListPendingMetadataBookmarks returns up to limit bookmarks waiting for
+// their page metadata, oldest first.
+func (r *repository) ListPendingMetadataBookmarks(ctx context.Context, limit int) ([]model.Bookmark, error) {
+	const op = "repository.ListPendingMetadataBookmarks"
+	log := r.log.With("op", op)
+
+	bookmarks := make([]model.Bookmark, 0)
+	err := r.db.WithContext(ctx).Model(&model.Bookmark{}).
+		Select("id", "url").
+		Where("metadata_pending = ?", true).
+		Order("id").Limit(limit).
+		Find(&bookmarks).Error
+	if err != nil {
+		log.Error("failed to list bookmarks pending metadata", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return bookmarks, nil
+}
+
+// UpdateBookmarkMetadata fills the empty title and icon of a bookmark and
+// clears the pending flag.
+func (r *repository) UpdateBookmarkMetadata(ctx context.Context, id uint, title, iconURL string) error {
+	const op = "repository.UpdateBookmarkMetadata"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Model(&model.Bookmark{}).Where("id = ?", id).UpdateColumns(map[string]any{
+		"title":            gorm.Expr("CASE WHEN title = '' THEN ? ELSE title END", title),
+		"icon_url":         gorm.Expr("CASE WHEN icon_url = '' THEN ? ELSE icon_url END", iconURL),
+		"metadata_pending": false,
+	}).Error
+	if err != nil {
+		log.Error("failed to update bookmark metadata", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
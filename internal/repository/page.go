@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	orderAsc  = "asc"
+	orderDesc = "desc"
+)
+
+// cursor marks the last row of a page. The next page starts right after it,
+// so rows inserted in the meantime neither shift nor repeat entries.
+type cursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    uint   `json:"i"`
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
+
+// sortKey orders a listing of T by one column. value renders the column of a
+// row for the cursor and parse turns that back into a query argument.
+type sortKey[T any] struct {
+	column string
+	order  string
+	value  func(*T) string
+	parse  func(string) (any, error)
+}
+
+func stringKey[T any](column, order string, value func(*T) string) sortKey[T] {
+	return sortKey[T]{
+		column: column,
+		order:  order,
+		value:  value,
+		parse:  func(s string) (any, error) { return s, nil },
+	}
+}
+
+func intKey[T any](column, order string, value func(*T) int64) sortKey[T] {
+	return sortKey[T]{
+		column: column,
+		order:  order,
+		value:  func(row *T) string { return strconv.FormatInt(value(row), 10) },
+		parse:  func(s string) (any, error) { return strconv.ParseInt(s, 10, 64) },
+	}
+}
+
+func timeKey[T any](column, order string, value func(*T) time.Time) sortKey[T] {
+	return sortKey[T]{
+		column: column,
+		order:  order,
+		value:  func(row *T) string { return value(row).Format(time.RFC3339Nano) },
+		parse:  func(s string) (any, error) { return time.Parse(time.RFC3339Nano, s) },
+	}
+}
+
+// listing describes the keyset pagination of a table. The id column breaks
+// ties between rows with equal sort values.
+type listing[T any] struct {
+	id    string
+	rowID func(*T) uint
+	keys  map[string]sortKey[T]
+}
+
+// pageQuery is a page request resolved against a listing.
+type pageQuery[T any] struct {
+	listing listing[T]
+	key     sortKey[T]
+	sort    string
+	order   string
+	after   *cursor
+	value   any
+	limit   int
+}
+
+func (l listing[T]) query(req model.PageRequest) (*pageQuery[T], error) {
+	sort := req.Sort
+	if sort == "" {
+		sort = model.SortCreated
+	}
+	key, ok := l.keys[sort]
+	if !ok {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Сортировка по этому полю не поддерживается")
+	}
+
+	q := &pageQuery[T]{
+		listing: l,
+		key:     key,
+		sort:    sort,
+		order:   req.Order,
+		limit:   req.Limit,
+	}
+	if q.order == "" {
+		q.order = key.order
+	}
+	if q.limit <= 0 || q.limit > model.MaxPageLimit {
+		q.limit = model.DefaultPageLimit
+	}
+
+	if req.Cursor != "" {
+		c, err := decodeCursor(req.Cursor)
+		if err != nil || c.Sort != q.sort || c.Order != q.order {
+			return nil, customerrors.New(customerrors.CodeInvalidRequest, "Неверный курсор")
+		}
+		if q.value, err = key.parse(c.Value); err != nil {
+			return nil, customerrors.New(customerrors.CodeInvalidRequest, "Неверный курсор")
+		}
+		q.after = &c
+	}
+
+	return q, nil
+}
+
+// scope orders the rows, skips those up to the cursor and fetches one row
+// more than the page holds, so page can tell whether another one follows.
+func (q *pageQuery[T]) scope(db *gorm.DB) *gorm.DB {
+	dir, cmp := "DESC", "<"
+	if q.order == orderAsc {
+		dir, cmp = "ASC", ">"
+	}
+	id, column := q.listing.id, q.key.column
+
+	if column == id {
+		if q.after != nil {
+			db = db.Where(id+" "+cmp+" ?", q.after.ID)
+		}
+		return db.Order(id + " " + dir).Limit(q.limit + 1)
+	}
+
+	if q.after != nil {
+		db = db.Where(fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND %[3]s %[2]s ?))", column, cmp, id), q.value, q.value, q.after.ID)
+	}
+	return db.Order(column + " " + dir).Order(id + " " + dir).Limit(q.limit + 1)
+}
+
+// page trims the rows fetched through scope to the page and returns the
+// cursor of the next one, empty on the last page.
+func (q *pageQuery[T]) page(rows []T) ([]T, string) {
+	if len(rows) <= q.limit {
+		return rows, ""
+	}
+
+	rows = rows[:q.limit]
+	last := &rows[len(rows)-1]
+	return rows, encodeCursor(cursor{
+		Sort:  q.sort,
+		Order: q.order,
+		Value: q.key.value(last),
+		ID:    q.listing.rowID(last),
+	})
+}
+
+// likePattern turns a search string into a case-insensitive LIKE pattern
+// matching it anywhere; use it with ESCAPE '\'.
+func likePattern(q string) string {
+	q = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(q))
+	return "%" + q + "%"
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/OxytocinGroup/theca-v3/internal/model"
 	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
@@ -12,10 +13,92 @@ import (
 )
 
 type Repository interface {
+	// Transaction runs fn against a repository bound to a single database transaction
+	Transaction(ctx context.Context, fn func(repo Repository) error) error
+
 	Register(ctx context.Context, user *model.User) error
 	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
 	GetUserByID(ctx context.Context, id uint) (*model.User, error)
 	SaveUser(ctx context.Context, user *model.User) error
+
+	CreateLink(ctx context.Context, link *model.Link) error
+	GetUserLink(ctx context.Context, userID, id uint) (*model.Link, error)
+	GetLinkByCode(ctx context.Context, domainID uint, code string) (*model.Link, error)
+	ListUserLinks(ctx context.Context, userID uint, filter model.LinkFilter, page model.PageRequest) ([]model.Link, string, error)
+	LinkCodeExists(ctx context.Context, domainID uint, code string) (bool, error)
+	TakenLinkCodes(ctx context.Context, domainID uint, codes []string) ([]string, error)
+	SaveLink(ctx context.Context, link *model.Link) error
+	DeleteLink(ctx context.Context, link *model.Link) error
+	MarkExpiredLinks(ctx context.Context, now time.Time) (int64, error)
+
+	ListDeletedLinks(ctx context.Context, userID uint) ([]model.Link, error)
+	GetDeletedUserLink(ctx context.Context, userID, id uint) (*model.Link, error)
+	RestoreLink(ctx context.Context, link *model.Link) error
+	PurgeLink(ctx context.Context, link *model.Link) error
+	PurgeDeletedLinks(ctx context.Context, before time.Time) (int64, error)
+
+	FindOrCreateTags(ctx context.Context, userID uint, names []string) ([]model.Tag, error)
+	ReplaceLinkTags(ctx context.Context, link *model.Link, tags []model.Tag) error
+	TagNameExists(ctx context.Context, userID uint, name string) (bool, error)
+	CreateTag(ctx context.Context, tag *model.Tag) error
+	GetUserTag(ctx context.Context, userID, id uint) (*model.Tag, error)
+	ListUserTags(ctx context.Context, userID uint) ([]model.Tag, error)
+	SaveTag(ctx context.Context, tag *model.Tag) error
+	DeleteTag(ctx context.Context, tag *model.Tag) error
+	ReplaceLinkRules(ctx context.Context, link *model.Link, rules []model.LinkRule) error
+	ReplaceLinkVariants(ctx context.Context, link *model.Link, variants []model.LinkVariant) error
+
+	CreateLinkRevision(ctx context.Context, rev *model.LinkRevision) error
+	SaveLinkWithRevision(ctx context.Context, link *model.Link, rev *model.LinkRevision) error
+	ListLinkRevisions(ctx context.Context, linkID uint) ([]model.LinkRevision, error)
+	GetLinkRevision(ctx context.Context, linkID, number uint) (*model.LinkRevision, error)
+
+	CreateCampaign(ctx context.Context, campaign *model.Campaign) error
+	GetUserCampaign(ctx context.Context, userID, id uint) (*model.Campaign, error)
+	ListUserCampaigns(ctx context.Context, userID uint) ([]model.Campaign, error)
+	SaveCampaign(ctx context.Context, campaign *model.Campaign) error
+	DeleteCampaign(ctx context.Context, campaign *model.Campaign) error
+
+	CreateBookmark(ctx context.Context, bookmark *model.Bookmark) error
+	GetUserBookmark(ctx context.Context, userID, id uint) (*model.Bookmark, error)
+	ListUserBookmarks(ctx context.Context, userID uint, page model.PageRequest) ([]model.Bookmark, string, error)
+	DeleteBookmark(ctx context.Context, bookmark *model.Bookmark) error
+	ListDeletedBookmarks(ctx context.Context, userID uint) ([]model.Bookmark, error)
+	GetDeletedUserBookmark(ctx context.Context, userID, id uint) (*model.Bookmark, error)
+	RestoreBookmark(ctx context.Context, bookmark *model.Bookmark) error
+	PurgeBookmark(ctx context.Context, bookmark *model.Bookmark) error
+	PurgeDeletedBookmarks(ctx context.Context, before time.Time) (int64, error)
+
+	ListPendingMetadataLinks(ctx context.Context, limit int) ([]model.Link, error)
+	UpdateLinkMetadata(ctx context.Context, id uint, title, description, iconURL string) error
+	ListPendingMetadataBookmarks(ctx context.Context, limit int) ([]model.Bookmark, error)
+	UpdateBookmarkMetadata(ctx context.Context, id uint, title, iconURL string) error
+
+	ListDueHealthLinks(ctx context.Context, before time.Time, limit int) ([]model.Link, error)
+	UpdateLinkHealth(ctx context.Context, id uint, health model.Health) error
+	CountBrokenLinks(ctx context.Context) (int64, error)
+	ListDueHealthBookmarks(ctx context.Context, before time.Time, limit int) ([]model.Bookmark, error)
+	UpdateBookmarkHealth(ctx context.Context, id uint, health model.Health) error
+	CountBrokenBookmarks(ctx context.Context) (int64, error)
+
+	CreateDomain(ctx context.Context, domain *model.Domain) error
+	GetUserDomain(ctx context.Context, userID, id uint) (*model.Domain, error)
+	GetDomainByHost(ctx context.Context, host string) (*model.Domain, error)
+	ListUserDomains(ctx context.Context, userID uint) ([]model.Domain, error)
+	SaveDomain(ctx context.Context, domain *model.Domain) error
+	VerifyDomain(ctx context.Context, domain *model.Domain) error
+	DeleteStaleDomainClaims(ctx context.Context, before time.Time) (int64, error)
+	DeleteDomain(ctx context.Context, domain *model.Domain) error
+	CountDomainLinks(ctx context.Context, domainID uint) (int64, error)
+
+	CreateClicks(ctx context.Context, clicks []model.Click) error
+	SummarizeClicks(ctx context.Context, userID uint, filter model.StatsFilter) (*model.ClickSummary, error)
+	CountClicksByAgent(ctx context.Context, userID uint, filter model.StatsFilter, versions bool, limit int) (*model.AgentBreakdown, error)
+	CountClicksByReferrer(ctx context.Context, userID uint, filter model.StatsFilter, limit int) (*model.ReferrerBreakdown, error)
+	CountClicksBySlot(ctx context.Context, userID uint, filter model.StatsFilter, slot time.Duration) (map[int64]int64, error)
+	EnsureVisitorSalts(ctx context.Context, salts []model.VisitorSalt) error
+	ListVisitorSketches(ctx context.Context, userID uint, filter model.VisitorsRequest) ([][]byte, error)
+	CountLinkClicksByVariant(ctx context.Context, linkID uint) (map[string]int64, error)
 }
 
 type repository struct {
@@ -30,6 +113,12 @@ func NewRepository(db *gorm.DB, log *slog.Logger) Repository {
 	}
 }
 
+func (r *repository) Transaction(ctx context.Context, fn func(repo Repository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&repository{db: tx, log: r.log})
+	})
+}
+
 func (r *repository) Register(ctx context.Context, user *model.User) error {
 	const op = "repository.Register"
 	log := r.log.With("op", op)
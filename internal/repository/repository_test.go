@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newTestRepository returns a repository on a fresh in-memory SQLite
+// database.
+func newTestRepository(t *testing.T) (Repository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: is a database of its own.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&model.User{}, &model.Domain{}, &model.Link{}, &model.LinkRevision{}, &model.Campaign{}, &model.LinkRule{}, &model.LinkVariant{}, &model.VisitorSalt{}); err != nil {
+		t.Fatal(err)
+	}
+	return NewRepository(db, slog.New(slog.NewTextHandler(io.Discard, nil))), db
+}
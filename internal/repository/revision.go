@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateLinkRevision stores rev as the next revision of its link. It locks
+// the link row to number the revision, so it has to run in the transaction
+// that changed the link.
+func (r *repository) CreateLinkRevision(ctx context.Context, rev *model.LinkRevision) error {
+	const op = "repository.CreateLinkRevision"
+	log := r.log.With("op", op)
+
+	// Concurrent edits of a link wait here for each other instead of both
+	// taking the same number.
+	var ids []uint
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.Link{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", rev.LinkID).
+		Pluck("id", &ids).Error
+	if err != nil {
+		log.Error("failed to lock link", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	var last uint
+	err = r.db.WithContext(ctx).Model(&model.LinkRevision{}).
+		Where("link_id = ?", rev.LinkID).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&last).Error
+	if err != nil {
+		log.Error("failed to get last revision number", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	rev.Number = last + 1
+	err = r.db.WithContext(ctx).Model(&model.LinkRevision{}).Create(rev).Error
+	if err != nil {
+		log.Error("failed to create link revision", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+// SaveLinkWithRevision saves the link, replaces its tags, rules and variants
+// and records the revision in a single transaction.
+func (r *repository) SaveLinkWithRevision(ctx context.Context, link *model.Link, rev *model.LinkRevision) error {
+	return r.Transaction(ctx, func(repo Repository) error {
+		if err := repo.SaveLink(ctx, link); err != nil {
+			return err
+		}
+		if err := repo.ReplaceLinkTags(ctx, link, link.Tags); err != nil {
+			return err
+		}
+		if err := repo.ReplaceLinkRules(ctx, link, link.Rules); err != nil {
+			return err
+		}
+		if err := repo.ReplaceLinkVariants(ctx, link, link.Variants); err != nil {
+			return err
+		}
+		return repo.CreateLinkRevision(ctx, rev)
+	})
+}
+
+func (r *repository) ListLinkRevisions(ctx context.Context, linkID uint) ([]model.LinkRevision, error) {
+	const op = "repository.ListLinkRevisions"
+	log := r.log.With("op", op)
+
+	revisions := make([]model.LinkRevision, 0)
+	err := r.db.WithContext(ctx).Model(&model.LinkRevision{}).Where("link_id = ?", linkID).Order("number DESC").Find(&revisions).Error
+	if err != nil {
+		log.Error("failed to list link revisions", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return revisions, nil
+}
+
+func (r *repository) GetLinkRevision(ctx context.Context, linkID, number uint) (*model.LinkRevision, error) {
+	const op = "repository.GetLinkRevision"
+	log := r.log.With("op", op)
+
+	var rev model.LinkRevision
+	err := r.db.WithContext(ctx).Model(&model.LinkRevision{}).Where("link_id = ? AND number = ?", linkID, number).First(&rev).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customerrors.New(customerrors.CodeDataNotFound, "Ревизия не найдена")
+		}
+		log.Error("failed to get link revision", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return &rev, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+)
+
+func TestCreateLinkRevisionNumbers(t *testing.T) {
+	repo, db := newTestRepository(t)
+	ctx := context.Background()
+
+	links := []model.Link{
+		{Code: "a", Destination: "https://example.com/a", UserID: 1},
+		{Code: "b", Destination: "https://example.com/b", UserID: 1},
+	}
+	if err := db.Create(&links).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		link uint
+		want uint
+	}{
+		{links[0].ID, 1},
+		{links[0].ID, 2},
+		{links[1].ID, 1},
+		{links[0].ID, 3},
+	} {
+		rev := &model.LinkRevision{LinkID: tt.link, Action: model.RevisionActionUpdate, Snapshot: model.JSONText("{}")}
+		err := repo.Transaction(ctx, func(repo Repository) error {
+			return repo.CreateLinkRevision(ctx, rev)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rev.Number != tt.want {
+			t.Fatalf("link %d got revision %d, want %d", tt.link, rev.Number, tt.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"gorm.io/gorm"
+)
+
+// ReplaceLinkRules swaps the targeting rules of a link for the given ones,
+// numbering them in order.
+func (r *repository) ReplaceLinkRules(ctx context.Context, link *model.Link, rules []model.LinkRule) error {
+	const op = "repository.ReplaceLinkRules"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("link_id = ?", link.ID).Delete(&model.LinkRule{}).Error; err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+
+		for i := range rules {
+			rules[i].ID = 0
+			rules[i].LinkID = link.ID
+			rules[i].Position = i
+		}
+		return tx.Create(&rules).Error
+	})
+	if err != nil {
+		log.Error("failed to replace link rules", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	link.Rules = rules
+	return nil
+}
+
+// byPosition orders preloaded rules and variants as they were submitted.
+func byPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
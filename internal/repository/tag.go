@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FindOrCreateTags returns the user's tags with the given names, creating the
+// missing ones.
+func (r *repository) FindOrCreateTags(ctx context.Context, userID uint, names []string) ([]model.Tag, error) {
+	const op = "repository.FindOrCreateTags"
+	log := r.log.With("op", op)
+
+	tags := make([]model.Tag, 0, len(names))
+	if len(names) == 0 {
+		return tags, nil
+	}
+
+	for _, name := range names {
+		tags = append(tags, model.Tag{UserID: userID, Name: name})
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error
+	if err != nil {
+		log.Error("failed to create tags", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	tags = tags[:0]
+	err = r.db.WithContext(ctx).Model(&model.Tag{}).Where("user_id = ? AND name IN ?", userID, names).Order("name").Find(&tags).Error
+	if err != nil {
+		log.Error("failed to get tags", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return tags, nil
+}
+
+func (r *repository) ReplaceLinkTags(ctx context.Context, link *model.Link, tags []model.Tag) error {
+	const op = "repository.ReplaceLinkTags"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Model(link).Association("Tags").Replace(tags)
+	if err != nil {
+		log.Error("failed to replace link tags", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+// TagNameExists reports whether the user already has a tag with the name.
+func (r *repository) TagNameExists(ctx context.Context, userID uint, name string) (bool, error) {
+	const op = "repository.TagNameExists"
+	log := r.log.With("op", op)
+
+	var c int64
+	err := r.db.WithContext(ctx).Model(&model.Tag{}).Where("user_id = ? AND name = ?", userID, name).Count(&c).Error
+	if err != nil {
+		log.Error("failed to count tags", "error", err)
+		return false, customerrors.FromGormError(err)
+	}
+
+	return c > 0, nil
+}
+
+func (r *repository) CreateTag(ctx context.Context, tag *model.Tag) error {
+	const op = "repository.CreateTag"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Model(&model.Tag{}).Create(tag).Error
+	if err != nil {
+		log.Error("failed to create tag", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetUserTag(ctx context.Context, userID, id uint) (*model.Tag, error) {
+	const op = "repository.GetUserTag"
+	log := r.log.With("op", op)
+
+	var tag model.Tag
+	err := r.db.WithContext(ctx).Model(&model.Tag{}).Where("id = ? AND user_id = ?", id, userID).First(&tag).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customerrors.New(customerrors.CodeDataNotFound, "Тег не найден")
+		}
+		log.Error("failed to get tag", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return &tag, nil
+}
+
+// ListUserTags returns the user's tags by name, each with the number of links
+// outside the trash carrying it.
+func (r *repository) ListUserTags(ctx context.Context, userID uint) ([]model.Tag, error) {
+	const op = "repository.ListUserTags"
+	log := r.log.With("op", op)
+
+	tags := make([]model.Tag, 0)
+	err := r.db.WithContext(ctx).Model(&model.Tag{}).
+		Select("tags.*, COUNT(links.id) AS link_count").
+		Joins("LEFT JOIN link_tags ON link_tags.tag_id = tags.id").
+		Joins("LEFT JOIN links ON links.id = link_tags.link_id AND links.deleted_at IS NULL").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id").
+		Order("tags.name").
+		Find(&tags).Error
+	if err != nil {
+		log.Error("failed to list tags", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return tags, nil
+}
+
+func (r *repository) SaveTag(ctx context.Context, tag *model.Tag) error {
+	const op = "repository.SaveTag"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Save(tag).Error
+	if err != nil {
+		log.Error("failed to save tag", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+// DeleteTag removes a tag and takes it off every link, trashed ones included.
+func (r *repository) DeleteTag(ctx context.Context, tag *model.Tag) error {
+	const op = "repository.DeleteTag"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("link_tags").Where("tag_id = ?", tag.ID).Delete(nil).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(tag).Error
+	})
+	if err != nil {
+		log.Error("failed to delete tag", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"gorm.io/gorm"
+)
+
+func (r *repository) ListDeletedLinks(ctx context.Context, userID uint) ([]model.Link, error) {
+	const op = "repository.ListDeletedLinks"
+	log := r.log.With("op", op)
+
+	links := make([]model.Link, 0)
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.Link{}).Preload("Tags").Preload("Rules", byPosition).Preload("Variants", byPosition).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).Order("deleted_at DESC").Find(&links).Error
+	if err != nil {
+		log.Error("failed to list deleted links", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return links, nil
+}
+
+func (r *repository) GetDeletedUserLink(ctx context.Context, userID, id uint) (*model.Link, error) {
+	const op = "repository.GetDeletedUserLink"
+	log := r.log.With("op", op)
+
+	var link model.Link
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.Link{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).First(&link).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customerrors.New(customerrors.CodeDataNotFound, "Ссылка не найдена в корзине")
+		}
+		log.Error("failed to get deleted link", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return &link, nil
+}
+
+func (r *repository) RestoreLink(ctx context.Context, link *model.Link) error {
+	const op = "repository.RestoreLink"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Unscoped().Model(link).Update("deleted_at", nil).Error
+	if err != nil {
+		log.Error("failed to restore link", "error", err)
+		return customerrors.FromGormError(err)
+	}
+	link.DeletedAt = gorm.DeletedAt{}
+
+	return nil
+}
+
+// PurgeLink removes a trashed link for good, together with its clicks,
+// visitor sketches, revisions, rules, variants and tag assignments.
+func (r *repository) PurgeLink(ctx context.Context, link *model.Link) error {
+	return r.purgeLinks(ctx, []uint{link.ID})
+}
+
+// PurgeDeletedLinks purges every link that was trashed before the given time
+// and returns how many were removed.
+func (r *repository) PurgeDeletedLinks(ctx context.Context, before time.Time) (int64, error) {
+	const op = "repository.PurgeDeletedLinks"
+	log := r.log.With("op", op)
+
+	var ids []uint
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.Link{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Pluck("id", &ids).Error
+	if err != nil {
+		log.Error("failed to find purgeable links", "error", err)
+		return 0, customerrors.FromGormError(err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := r.purgeLinks(ctx, ids); err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
+func (r *repository) purgeLinks(ctx context.Context, ids []uint) error {
+	const op = "repository.purgeLinks"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("link_id IN ?", ids).Delete(&model.Click{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("link_id IN ?", ids).Delete(&model.VisitorSketch{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("link_id IN ?", ids).Delete(&model.LinkRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("link_id IN ?", ids).Delete(&model.LinkRule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("link_id IN ?", ids).Delete(&model.LinkVariant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM link_tags WHERE link_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&model.Link{}).Error
+	})
+	if err != nil {
+		log.Error("failed to purge links", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+func (r *repository) ListDeletedBookmarks(ctx context.Context, userID uint) ([]model.Bookmark, error) {
+	const op = "repository.ListDeletedBookmarks"
+	log := r.log.With("op", op)
+
+	bookmarks := make([]model.Bookmark, 0)
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.Bookmark{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).Order("deleted_at DESC").Find(&bookmarks).Error
+	if err != nil {
+		log.Error("failed to list deleted bookmarks", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return bookmarks, nil
+}
+
+func (r *repository) GetDeletedUserBookmark(ctx context.Context, userID, id uint) (*model.Bookmark, error) {
+	const op = "repository.GetDeletedUserBookmark"
+	log := r.log.With("op", op)
+
+	var bookmark model.Bookmark
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.Bookmark{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).First(&bookmark).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customerrors.New(customerrors.CodeDataNotFound, "Закладка не найдена в корзине")
+		}
+		log.Error("failed to get deleted bookmark", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return &bookmark, nil
+}
+
+func (r *repository) RestoreBookmark(ctx context.Context, bookmark *model.Bookmark) error {
+	const op = "repository.RestoreBookmark"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Unscoped().Model(bookmark).Update("deleted_at", nil).Error
+	if err != nil {
+		log.Error("failed to restore bookmark", "error", err)
+		return customerrors.FromGormError(err)
+	}
+	bookmark.DeletedAt = gorm.DeletedAt{}
+
+	return nil
+}
+
+func (r *repository) PurgeBookmark(ctx context.Context, bookmark *model.Bookmark) error {
+	const op = "repository.PurgeBookmark"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Unscoped().Delete(bookmark).Error
+	if err != nil {
+		log.Error("failed to purge bookmark", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+// PurgeDeletedBookmarks purges every bookmark that was trashed before the
+// given time and returns how many were removed.
+func (r *repository) PurgeDeletedBookmarks(ctx context.Context, before time.Time) (int64, error) {
+	const op = "repository.PurgeDeletedBookmarks"
+	log := r.log.With("op", op)
+
+	res := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&model.Bookmark{})
+	if res.Error != nil {
+		log.Error("failed to purge bookmarks", "error", res.Error)
+		return 0, customerrors.FromGormError(res.Error)
+	}
+
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"gorm.io/gorm"
+)
+
+// ReplaceLinkVariants swaps the A/B split variants of a link for the given
+// ones, numbering them in order.
+func (r *repository) ReplaceLinkVariants(ctx context.Context, link *model.Link, variants []model.LinkVariant) error {
+	const op = "repository.ReplaceLinkVariants"
+	log := r.log.With("op", op)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("link_id = ?", link.ID).Delete(&model.LinkVariant{}).Error; err != nil {
+			return err
+		}
+		if len(variants) == 0 {
+			return nil
+		}
+
+		for i := range variants {
+			variants[i].ID = 0
+			variants[i].LinkID = link.ID
+			variants[i].Position = i
+		}
+		return tx.Create(&variants).Error
+	})
+	if err != nil {
+		log.Error("failed to replace link variants", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	link.Variants = variants
+	return nil
+}
+
+// CountLinkClicksByVariant returns the number of clicks per variant label.
+// Clicks served without a split are counted under the empty label.
+func (r *repository) CountLinkClicksByVariant(ctx context.Context, linkID uint) (map[string]int64, error) {
+	const op = "repository.CountLinkClicksByVariant"
+	log := r.log.With("op", op)
+
+	var rows []struct {
+		Variant string
+		Clicks  int64
+	}
+	err := r.db.WithContext(ctx).Model(&model.Click{}).
+		Select("variant, COUNT(*) AS clicks").
+		Where("link_id = ?", linkID).
+		Group("variant").
+		Scan(&rows).Error
+	if err != nil {
+		log.Error("failed to count clicks by variant", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Variant] = row.Clicks
+	}
+	return counts, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/hll"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EnsureVisitorSalts stores each salt unless a salt for its day exists
+// already, in which case it is replaced by the stored one, and deletes the
+// salts of every other day.
+func (r *repository) EnsureVisitorSalts(ctx context.Context, salts []model.VisitorSalt) error {
+	const op = "repository.EnsureVisitorSalts"
+	log := r.log.With("op", op)
+
+	days := make([]string, len(salts))
+	for i := range salts {
+		days[i] = salts[i].Day
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&salts).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("day NOT IN ?", days).Delete(&model.VisitorSalt{}).Error; err != nil {
+			return err
+		}
+		for i := range salts {
+			if err := tx.Where("day = ?", salts[i].Day).First(&salts[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to ensure visitor salts", "error", err)
+		return customerrors.FromGormError(err)
+	}
+
+	return nil
+}
+
+// ListVisitorSketches returns the visitor sketches of the user's links
+// matching the filter over its days. From and To must be set.
+func (r *repository) ListVisitorSketches(ctx context.Context, userID uint, filter model.VisitorsRequest) ([][]byte, error) {
+	const op = "repository.ListVisitorSketches"
+	log := r.log.With("op", op)
+
+	query := r.db.WithContext(ctx).Model(&model.VisitorSketch{}).
+		Joins("JOIN links ON links.id = visitor_sketches.link_id AND links.deleted_at IS NULL").
+		Scopes(filterLinks(userID, filter.LinkFilter)).
+		Where("visitor_sketches.day BETWEEN ? AND ?", filter.From.Format(model.VisitorDayFormat), filter.To.Format(model.VisitorDayFormat))
+	if filter.LinkID != 0 {
+		query = query.Where("visitor_sketches.link_id = ?", filter.LinkID)
+	}
+
+	sketches := make([][]byte, 0)
+	if err := query.Pluck("visitor_sketches.sketch", &sketches).Error; err != nil {
+		log.Error("failed to list visitor sketches", "error", err)
+		return nil, customerrors.FromGormError(err)
+	}
+
+	return sketches, nil
+}
+
+type sketchKey struct {
+	day    string
+	linkID uint
+}
+
+// mergeVisitorSketches adds the visitors of clicks to the sketches of their
+// links and days. Rows are locked in a fixed order, so concurrent batches
+// cannot deadlock on each other.
+func mergeVisitorSketches(tx *gorm.DB, clicks []model.Click) error {
+	visitors := make(map[sketchKey][]string)
+	for _, click := range clicks {
+		if click.IPHash == "" {
+			continue
+		}
+		key := sketchKey{day: click.CreatedAt.UTC().Format(model.VisitorDayFormat), linkID: click.LinkID}
+		visitors[key] = append(visitors[key], click.IPHash)
+	}
+	keys := slices.SortedFunc(maps.Keys(visitors), func(a, b sketchKey) int {
+		return cmp.Or(cmp.Compare(a.linkID, b.linkID), strings.Compare(a.day, b.day))
+	})
+
+	for _, key := range keys {
+		// Insert an empty sketch first, so there is a row to lock even for
+		// the first clicks of the day.
+		row := model.VisitorSketch{Day: key.day, LinkID: key.linkID, Sketch: hll.New().Marshal()}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error; err != nil {
+			return err
+		}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("link_id = ? AND day = ?", key.linkID, key.day).
+			First(&row).Error
+		if err != nil {
+			return err
+		}
+
+		sketch, err := hll.Unmarshal(row.Sketch)
+		if err != nil {
+			// An unreadable sketch would otherwise fail every batch of
+			// the day; start it over.
+			sketch = hll.New()
+		}
+		for _, hash := range visitors[key] {
+			sketch.Add([]byte(hash))
+		}
+
+		err = tx.Model(&model.VisitorSketch{}).
+			Where("link_id = ? AND day = ?", key.linkID, key.day).
+			Update("sketch", sketch.Marshal()).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
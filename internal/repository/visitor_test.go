@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+)
+
+func TestEnsureVisitorSalts(t *testing.T) {
+	repo, db := newTestRepository(t)
+	ctx := context.Background()
+
+	first := []model.VisitorSalt{{Day: "2026-01-01", Salt: []byte("first")}, {Day: "2026-01-02", Salt: []byte("next")}}
+	if err := repo.EnsureVisitorSalts(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another instance racing for the same days gets the stored salts.
+	again := []model.VisitorSalt{{Day: "2026-01-01", Salt: []byte("again")}, {Day: "2026-01-02", Salt: []byte("again")}}
+	if err := repo.EnsureVisitorSalts(ctx, again); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again[0].Salt, []byte("first")) || !bytes.Equal(again[1].Salt, []byte("next")) {
+		t.Fatalf("got salts %q and %q, want the stored ones", again[0].Salt, again[1].Salt)
+	}
+
+	// A day later the first day's salt is deleted and the second one kept.
+	later := []model.VisitorSalt{{Day: "2026-01-02", Salt: []byte("later")}, {Day: "2026-01-03", Salt: []byte("later")}}
+	if err := repo.EnsureVisitorSalts(ctx, later); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(later[0].Salt, []byte("next")) {
+		t.Fatalf("got salt %q, want the stored one", later[0].Salt)
+	}
+	var days []string
+	if err := db.Model(&model.VisitorSalt{}).Order("day").Pluck("day", &days).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 2 || days[0] != "2026-01-02" || days[1] != "2026-01-03" {
+		t.Fatalf("salts of days %v are kept, want 2026-01-02 and 2026-01-03", days)
+	}
+}
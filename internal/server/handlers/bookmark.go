@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"log/slog"
+
+	"github.com/OxytocinGroup/theca-v3/internal/metrics"
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	errors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create bookmark
+// @Description Create a new bookmark
+// @Tags bookmark
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param createBookmarkRequest body model.CreateBookmarkRequest true "Create bookmark request"
+// @Success 200 {object} model.Bookmark
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Router /api/bookmarks [post]
+func (h *Handler) CreateBookmark(c *gin.Context) {
+	const op = "handler.createBookmark"
+	log := h.log.With(slog.String("op", op))
+
+	var req model.CreateBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Debug("binding json", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	bookmark, err := h.service.CreateBookmark(c.Request.Context(), c.GetUint("userID"), req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "bookmark_create_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, bookmark)
+}
+
+// @Summary List bookmarks
+// @Description List bookmarks owned by the current user
+// @Tags bookmark
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Search in title and URL"
+// @Param sort query string false "Sort key" Enums(created, title)
+// @Param order query string false "Sort order, descending by default except for title" Enums(asc, desc)
+// @Param limit query int false "Page size, 50 by default and at most 100"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} errors.Response{data=[]model.Bookmark} "Page of bookmarks with the cursor of the next one"
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Router /api/bookmarks [get]
+func (h *Handler) ListBookmarks(c *gin.Context) {
+	const op = "handler.ListBookmarks"
+	log := h.log.With(slog.String("op", op))
+
+	var page model.PageRequest
+	if err := c.ShouldBindQuery(&page); err != nil {
+		log.Debug("binding query", "err", err, "page", page)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	bookmarks, next, err := h.service.ListBookmarks(c.Request.Context(), c.GetUint("userID"), page)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "bookmark_list_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithPage(c, bookmarks, next)
+}
+
+// @Summary Delete bookmark
+// @Description Move a bookmark owned by the current user to the trash
+// @Tags bookmark
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bookmark ID"
+// @Success 200
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/bookmarks/{id} [delete]
+func (h *Handler) DeleteBookmark(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteBookmark(c.Request.Context(), c.GetUint("userID"), id); err != nil {
+		metrics.RecordError(c.Request.Context(), "bookmark_delete_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, "Bookmark moved to trash")
+}
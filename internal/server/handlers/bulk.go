@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/OxytocinGroup/theca-v3/internal/metrics"
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	errors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	bulkModeAtomic     = "atomic"
+	bulkModeBestEffort = "best_effort"
+
+	bulkMaxBodyBytes = 10 << 20
+)
+
+// @Summary Bulk create links
+// @Description Create many links from a JSON array or a CSV upload with destination, alias, title and tags columns
+// @Tags link
+// @Accept json
+// @Accept text/csv
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param mode query string false "atomic (default) or best_effort"
+// @Param links body []model.CreateLinkRequest false "Links to create"
+// @Param file formData file false "CSV file"
+// @Success 200 {object} model.BulkLinksResponse
+// @Failure 400 {object} errors.Error
+// @Router /api/links/bulk [post]
+func (h *Handler) BulkCreateLinks(c *gin.Context) {
+	const op = "handler.bulkCreateLinks"
+	log := h.log.With(slog.String("op", op))
+
+	mode := c.DefaultQuery("mode", bulkModeAtomic)
+	if mode != bulkModeAtomic && mode != bulkModeBestEffort {
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неизвестный режим пакетного создания"))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, bulkMaxBodyBytes)
+	reqs, err := h.readBulkLinks(c)
+	if err != nil {
+		log.Debug("reading bulk links", "err", err)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	rows := make([]model.BulkLinkRow, len(reqs))
+	for i := range reqs {
+		rows[i] = model.BulkLinkRow{Request: reqs[i], Err: validateBulkRow(&reqs[i])}
+	}
+
+	resp, err := h.service.BulkCreateLinks(c.Request.Context(), c.GetUint("userID"), rows, mode == bulkModeAtomic)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "link_bulk_create_error", c.Request.URL.Path, c.Request.Method)
+		if resp != nil {
+			errors.RespondWithErrorData(c, err, resp)
+			return
+		}
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, resp)
+}
+
+func (h *Handler) readBulkLinks(c *gin.Context) ([]model.CreateLinkRequest, error) {
+	var (
+		reqs []model.CreateLinkRequest
+		err  error
+	)
+
+	switch c.ContentType() {
+	case "text/csv":
+		reqs, err = parseBulkCSV(c.Request.Body)
+	case binding.MIMEMultipartPOSTForm:
+		file, ferr := c.FormFile("file")
+		if ferr != nil {
+			return nil, errors.New(errors.CodeInvalidRequest, "Не найден CSV файл в поле file")
+		}
+		f, ferr := file.Open()
+		if ferr != nil {
+			return nil, errors.NewWithError(ferr, errors.CodeInvalidRequest, "Не удалось прочитать CSV файл")
+		}
+		defer f.Close()
+		reqs, err = parseBulkCSV(f)
+	default:
+		err = json.NewDecoder(c.Request.Body).Decode(&reqs)
+		if err != nil {
+			err = errors.NewWithError(err, errors.CodeInvalidRequest, "Неверный формат запроса")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reqs) == 0 {
+		return nil, errors.New(errors.CodeInvalidRequest, "Пакет не содержит ссылок")
+	}
+	if len(reqs) > h.cfg.BulkMaxRows {
+		return nil, errors.New(errors.CodeInvalidRequest, "Слишком много ссылок в одном пакете")
+	}
+	return reqs, nil
+}
+
+// parseBulkCSV reads destination, alias, title and tags columns. A header
+// row may name the columns in any order and add utm_source, utm_medium,
+// utm_campaign, utm_term and utm_content; without one the first four columns
+// are taken positionally. Tags within a cell are separated by ';' or ','.
+func parseBulkCSV(r io.Reader) ([]model.CreateLinkRequest, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, errors.NewWithError(err, errors.CodeInvalidRequest, "Неверный формат CSV")
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{"destination": 0, "alias": 1, "title": 2, "tags": 3}
+	if containsFold(records[0], "destination") {
+		columns = make(map[string]int, len(records[0]))
+		for i, name := range records[0] {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		records = records[1:]
+	}
+
+	cell := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	reqs := make([]model.CreateLinkRequest, 0, len(records))
+	for _, record := range records {
+		req := model.CreateLinkRequest{
+			Destination: cell(record, "destination"),
+			Alias:       cell(record, "alias"),
+			Title:       cell(record, "title"),
+			UTM: model.UTM{
+				Source:   cell(record, "utm_source"),
+				Medium:   cell(record, "utm_medium"),
+				Campaign: cell(record, "utm_campaign"),
+				Term:     cell(record, "utm_term"),
+				Content:  cell(record, "utm_content"),
+			},
+		}
+		if tags := cell(record, "tags"); tags != "" {
+			req.Tags = strings.FieldsFunc(tags, func(r rune) bool { return r == ';' || r == ',' })
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), target) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateBulkRow applies the binding rules of CreateLinkRequest to a single
+// row, so one bad row does not reject the whole batch.
+func validateBulkRow(req *model.CreateLinkRequest) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return errors.NewWithError(err, errors.CodeInvalidRequest, "Неверный формат строки")
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"log/slog"
+
+	"github.com/OxytocinGroup/theca-v3/internal/metrics"
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	errors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create campaign
+// @Description Create a campaign that groups links and holds UTM presets for them
+// @Tags campaign
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param createCampaignRequest body model.CreateCampaignRequest true "Create campaign request"
+// @Success 200 {object} model.Campaign
+// @Failure 400 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Router /api/campaigns [post]
+func (h *Handler) CreateCampaign(c *gin.Context) {
+	const op = "handler.createCampaign"
+	log := h.log.With(slog.String("op", op))
+
+	var req model.CreateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Debug("binding json", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	campaign, err := h.service.CreateCampaign(c.Request.Context(), c.GetUint("userID"), req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "campaign_create_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, campaign)
+}
+
+// @Summary List campaigns
+// @Description List campaigns of the current user
+// @Tags campaign
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Campaign
+// @Failure 401 {object} errors.Error
+// @Router /api/campaigns [get]
+func (h *Handler) ListCampaigns(c *gin.Context) {
+	campaigns, err := h.service.ListCampaigns(c.Request.Context(), c.GetUint("userID"))
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, campaigns)
+}
+
+// @Summary Get campaign
+// @Description Get a campaign of the current user
+// @Tags campaign
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Campaign ID"
+// @Success 200 {object} model.Campaign
+// @Failure 404 {object} errors.Error
+// @Router /api/campaigns/{id} [get]
+func (h *Handler) GetCampaign(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	campaign, err := h.service.GetCampaign(c.Request.Context(), c.GetUint("userID"), id)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, campaign)
+}
+
+// @Summary Update campaign
+// @Description Rename a campaign or change its UTM presets
+// @Tags campaign
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Campaign ID"
+// @Param updateCampaignRequest body model.UpdateCampaignRequest true "Update campaign request"
+// @Success 200 {object} model.Campaign
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/campaigns/{id} [patch]
+func (h *Handler) UpdateCampaign(c *gin.Context) {
+	const op = "handler.updateCampaign"
+	log := h.log.With(slog.String("op", op))
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Debug("binding json", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	campaign, err := h.service.UpdateCampaign(c.Request.Context(), c.GetUint("userID"), id, req)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, campaign)
+}
+
+// @Summary Delete campaign
+// @Description Delete a campaign; its links are kept and detached
+// @Tags campaign
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Campaign ID"
+// @Success 200
+// @Failure 404 {object} errors.Error
+// @Router /api/campaigns/{id} [delete]
+func (h *Handler) DeleteCampaign(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteCampaign(c.Request.Context(), c.GetUint("userID"), id); err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, "Campaign deleted successfully")
+}
+
+// @Summary Campaign stats
+// @Description Aggregate the clicks across all links of a campaign, optionally within a period
+// @Tags campaign
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Campaign ID"
+// @Param tag query []string false "Only links carrying every given tag" collectionFormat(multi)
+// @Param from query string false "Start of the period (RFC 3339, inclusive)"
+// @Param to query string false "End of the period (RFC 3339, exclusive)"
+// @Success 200 {object} model.ClickSummary
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/campaigns/{id}/stats [get]
+func (h *Handler) CampaignStats(c *gin.Context) {
+	const op = "handler.CampaignStats"
+	log := h.log.With(slog.String("op", op))
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var filter model.StatsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		log.Debug("binding query", "err", err, "filter", filter)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	summary, err := h.service.CampaignStats(c.Request.Context(), c.GetUint("userID"), id, filter)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "campaign_stats_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, summary)
+}
+
+// @Summary Campaign referrers
+// @Description Break the clicks across all links of a campaign down by referrer class and list the top referrer hosts
+// @Tags campaign
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Campaign ID"
+// @Param limit query int false "Number of hosts to list (1-100, 10 by default)"
+// @Param tag query []string false "Only links carrying every given tag" collectionFormat(multi)
+// @Param from query string false "Start of the period (RFC 3339, inclusive)"
+// @Param to query string false "End of the period (RFC 3339, exclusive)"
+// @Success 200 {object} model.ReferrerBreakdown
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/campaigns/{id}/referrers [get]
+func (h *Handler) CampaignReferrers(c *gin.Context) {
+	const op = "handler.CampaignReferrers"
+	log := h.log.With(slog.String("op", op))
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.ReferrersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Debug("binding query", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	breakdown, err := h.service.CampaignReferrers(c.Request.Context(), c.GetUint("userID"), id, req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "campaign_stats_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, breakdown)
+}
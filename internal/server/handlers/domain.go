@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"log/slog"
+
+	"github.com/OxytocinGroup/theca-v3/internal/metrics"
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	errors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create domain
+// @Description Add a custom domain; it serves links once verified
+// @Tags domain
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param createDomainRequest body model.CreateDomainRequest true "Create domain request"
+// @Success 200 {object} model.Domain
+// @Failure 400 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Router /api/domains [post]
+func (h *Handler) CreateDomain(c *gin.Context) {
+	const op = "handler.createDomain"
+	log := h.log.With(slog.String("op", op))
+
+	var req model.CreateDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Debug("binding json", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	domain, err := h.service.CreateDomain(c.Request.Context(), c.GetUint("userID"), req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "domain_create_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, domain)
+}
+
+// @Summary List domains
+// @Description List custom domains of the current user
+// @Tags domain
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Domain
+// @Failure 401 {object} errors.Error
+// @Router /api/domains [get]
+func (h *Handler) ListDomains(c *gin.Context) {
+	domains, err := h.service.ListDomains(c.Request.Context(), c.GetUint("userID"))
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, domains)
+}
+
+// @Summary Get domain
+// @Description Get a custom domain with its verification challenge
+// @Tags domain
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Domain ID"
+// @Success 200 {object} model.Domain
+// @Failure 404 {object} errors.Error
+// @Router /api/domains/{id} [get]
+func (h *Handler) GetDomain(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	domain, err := h.service.GetDomain(c.Request.Context(), c.GetUint("userID"), id)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, domain)
+}
+
+// @Summary Update domain
+// @Description Update root, 404 and robots.txt behavior of a domain
+// @Tags domain
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Domain ID"
+// @Param updateDomainRequest body model.UpdateDomainRequest true "Update domain request"
+// @Success 200 {object} model.Domain
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/domains/{id} [patch]
+func (h *Handler) UpdateDomain(c *gin.Context) {
+	const op = "handler.updateDomain"
+	log := h.log.With(slog.String("op", op))
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Debug("binding json", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	domain, err := h.service.UpdateDomain(c.Request.Context(), c.GetUint("userID"), id, req)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, domain)
+}
+
+// @Summary Delete domain
+// @Description Delete a custom domain that has no links
+// @Tags domain
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Domain ID"
+// @Success 200
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Router /api/domains/{id} [delete]
+func (h *Handler) DeleteDomain(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteDomain(c.Request.Context(), c.GetUint("userID"), id); err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, "Domain deleted successfully")
+}
+
+// @Summary Verify domain
+// @Description Check the DNS TXT challenge of a domain
+// @Tags domain
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Domain ID"
+// @Success 200 {object} model.Domain
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/domains/{id}/verify [post]
+func (h *Handler) VerifyDomain(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	domain, err := h.service.VerifyDomain(c.Request.Context(), c.GetUint("userID"), id)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "domain_verify_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, domain)
+}
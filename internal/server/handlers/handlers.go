@@ -3,20 +3,31 @@ package handlers
 import (
 	"log/slog"
 
+	"github.com/OxytocinGroup/theca-v3/internal/config"
 	"github.com/OxytocinGroup/theca-v3/internal/metrics"
 	"github.com/OxytocinGroup/theca-v3/internal/model"
 	"github.com/OxytocinGroup/theca-v3/internal/service"
 	errors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/ratelimit"
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	service service.Service
-	log     *slog.Logger
+	service       service.Service
+	log           *slog.Logger
+	cfg           *config.Config
+	unlockLimiter *ratelimit.FailureLimiter
+	linkLimiter   *ratelimit.FailureLimiter
 }
 
-func NewHandler(service service.Service, log *slog.Logger) *Handler {
-	return &Handler{service: service, log: log}
+func NewHandler(service service.Service, log *slog.Logger, cfg *config.Config) *Handler {
+	return &Handler{
+		service:       service,
+		log:           log,
+		cfg:           cfg,
+		unlockLimiter: ratelimit.NewFailureLimiter(cfg.UnlockMaxFailures, cfg.UnlockFailWindow),
+		linkLimiter:   ratelimit.NewFailureLimiter(cfg.UnlockLinkFailures, cfg.UnlockFailWindow),
+	}
 }
 
 // @Summary Register
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/OxytocinGroup/theca-v3/internal/metrics"
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	errors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create link
+// @Description Create a new short link
+// @Tags link
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param createLinkRequest body model.CreateLinkRequest true "Create link request"
+// @Success 200 {object} model.Link
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /api/links [post]
+func (h *Handler) CreateLink(c *gin.Context) {
+	const op = "handler.createLink"
+	log := h.log.With(slog.String("op", op))
+
+	var req model.CreateLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Debug("binding json", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	link, err := h.service.CreateLink(c.Request.Context(), c.GetUint("userID"), req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "link_create_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, link)
+}
+
+// @Summary List links
+// @Description List links owned by the current user
+// @Tags link
+// @Produce json
+// @Security BearerAuth
+// @Param tag query []string false "Only links carrying every given tag" collectionFormat(multi)
+// @Param campaign_id query int false "Only links of the campaign"
+// @Param broken query bool false "Only links whose destination is (or is not) flagged as broken"
+// @Param q query string false "Search in title, destination and short code"
+// @Param sort query string false "Sort key" Enums(created, updated, clicks, title)
+// @Param order query string false "Sort order, descending by default except for title" Enums(asc, desc)
+// @Param limit query int false "Page size, 50 by default and at most 100"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} errors.Response{data=[]model.Link} "Page of links with the cursor of the next one"
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /api/links [get]
+func (h *Handler) ListLinks(c *gin.Context) {
+	const op = "handler.ListLinks"
+	log := h.log.With(slog.String("op", op))
+
+	var filter model.LinkFilter
+	var page model.PageRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		log.Debug("binding query", "err", err, "filter", filter)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+	if err := c.ShouldBindQuery(&page); err != nil {
+		log.Debug("binding query", "err", err, "page", page)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	links, next, err := h.service.ListLinks(c.Request.Context(), c.GetUint("userID"), filter, page)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "link_list_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithPage(c, links, next)
+}
+
+// @Summary Get link
+// @Description Get a link owned by the current user
+// @Tags link
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Link ID"
+// @Success 200 {object} model.Link
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/links/{id} [get]
+func (h *Handler) GetLink(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	link, err := h.service.GetLink(c.Request.Context(), c.GetUint("userID"), id)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, link)
+}
+
+// @Summary Update link
+// @Description Update a link owned by the current user
+// @Tags link
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Link ID"
+// @Param updateLinkRequest body model.UpdateLinkRequest true "Update link request"
+// @Success 200 {object} model.Link
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/links/{id} [patch]
+func (h *Handler) UpdateLink(c *gin.Context) {
+	const op = "handler.updateLink"
+	log := h.log.With(slog.String("op", op))
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Debug("binding json", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	link, err := h.service.UpdateLink(c.Request.Context(), c.GetUint("userID"), id, req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "link_update_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, link)
+}
+
+// @Summary Delete link
+// @Description Move a link owned by the current user to the trash
+// @Tags link
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Link ID"
+// @Success 200
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/links/{id} [delete]
+func (h *Handler) DeleteLink(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteLink(c.Request.Context(), c.GetUint("userID"), id); err != nil {
+		metrics.RecordError(c.Request.Context(), "link_delete_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, "Link moved to trash")
+}
+
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный идентификатор"))
+		return 0, false
+	}
+	return uint(id), true
+}
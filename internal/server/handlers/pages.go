@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+const (
+	pageNotFound = "not_found"
+	pageGone     = "gone"
+	pagePassword = "password"
+	pageDeepLink = "deeplink"
+	pagePreview  = "preview"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+// pages holds one template set per page, each combined with the shared layout.
+var pages = mustParsePages(pageNotFound, pageGone, pagePassword, pageDeepLink, pagePreview)
+
+func mustParsePages(names ...string) map[string]*template.Template {
+	parsed := make(map[string]*template.Template, len(names))
+	for _, name := range names {
+		parsed[name] = template.Must(template.ParseFS(templatesFS, "templates/layout.html", "templates/"+name+".html"))
+	}
+	return parsed
+}
+
+func renderPage(c *gin.Context, status int, name string, data any) {
+	c.Header("Cache-Control", "no-store")
+	c.Render(status, render.HTML{Template: pages[name], Name: "layout", Data: data})
+}
+
+func renderNotFound(c *gin.Context) {
+	renderPage(c, http.StatusNotFound, pageNotFound, nil)
+}
+
+func renderGone(c *gin.Context, fallbackURL string) {
+	renderPage(c, http.StatusGone, pageGone, gin.H{"FallbackURL": fallbackURL})
+}
+
+func renderPassword(c *gin.Context, status int, action, message string) {
+	renderPage(c, status, pagePassword, gin.H{"Action": action, "Error": message})
+}
+
+func renderPreview(c *gin.Context, link *model.Link, continueURL string) {
+	renderPage(c, http.StatusOK, pagePreview, gin.H{
+		"Title":       link.Title,
+		"Destination": link.Destination,
+		"CreatedAt":   link.CreatedAt,
+		"ContinueURL": continueURL,
+	})
+}
+
+// renderDeepLink serves the page that tries to open the app and falls back to
+// fallbackURL when the app is not installed. The app URL has already been
+// checked against unsafe schemes, so it is passed to the template as trusted.
+func renderDeepLink(c *gin.Context, appURL, fallbackURL string) {
+	renderPage(c, http.StatusOK, pageDeepLink, gin.H{"AppURL": template.URL(appURL), "FallbackURL": fallbackURL})
+}
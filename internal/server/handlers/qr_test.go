@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"github.com/OxytocinGroup/theca-v3/internal/repository"
+	"github.com/OxytocinGroup/theca-v3/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// qrRepo serves a single link on the default domain.
+type qrRepo struct {
+	repository.Repository
+	link model.Link
+}
+
+func (r *qrRepo) GetUserLink(context.Context, uint, uint) (*model.Link, error) {
+	link := r.link
+	return &link, nil
+}
+
+func TestLinkQRCode(t *testing.T) {
+	cfg := testConfig()
+	cfg.CodeStrategy, cfg.CodeLength, cfg.CodeMaxAttempts = "random", 7, 5
+	cfg.DefaultDomain, cfg.ShortLinkScheme = "via.test", "https"
+
+	repo := &qrRepo{link: model.Link{ID: 1, UserID: 1, Code: "abc"}}
+	svc, err := service.NewService(repo, slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	h := NewHandler(svc, slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+	r := gin.New()
+	r.GET("/links/:id/qr", h.LinkQRCode)
+
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		check       func(t *testing.T, body []byte)
+	}{
+		{"png", "", "image/png", func(t *testing.T, body []byte) {
+			img, err := png.Decode(bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); b.Dx() != 512 || b.Dy() != 512 {
+				t.Fatalf("got a %dx%d image, want 512x512", b.Dx(), b.Dy())
+			}
+		}},
+		{"svg", "?format=svg&fg=336699", "image/svg+xml", func(t *testing.T, body []byte) {
+			if !bytes.HasPrefix(body, []byte("<?xml")) || !bytes.Contains(body, []byte(`fill="#336699"`)) {
+				t.Fatalf("unexpected SVG:\n%s", body)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/links/1/qr"+tt.query, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+				t.Fatalf("got content type %q, want %q", got, tt.contentType)
+			}
+			tt.check(t, w.Body.Bytes())
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/OxytocinGroup/theca-v3/internal/metrics"
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	errors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultRobotsTxt = "User-agent: *\nDisallow: /\n"
+
+// previewSuffix appended to a short code shows the preview page instead of
+// redirecting, as does the preview=1 query parameter.
+const previewSuffix = "+"
+
+// @Summary Redirect
+// @Description Resolve a short code and redirect to its destination. A "+" suffix on the code or preview=1 shows a preview page instead, which does not count as a click.
+// @Tags redirect
+// @Produce html
+// @Param code path string true "Short code"
+// @Param preview query bool false "Show the preview page"
+// @Success 301
+// @Success 302
+// @Success 307
+// @Success 308
+// @Success 200 "App launch page for deep links"
+// @Failure 404
+// @Failure 410
+// @Router /{code} [get]
+func (h *Handler) Redirect(c *gin.Context) {
+	const op = "handler.redirect"
+	log := h.log.With(slog.String("op", op))
+
+	link, ok := h.resolveLink(c)
+	if !ok {
+		return
+	}
+
+	if link.PasswordHash != "" {
+		token, _ := c.Cookie(unlockCookieName(link))
+		if !h.service.VerifyLinkUnlock(link, token) {
+			renderPassword(c, http.StatusOK, c.Request.URL.Path, "")
+			return
+		}
+	}
+
+	if isPreviewRequest(c) {
+		renderPreview(c, link, "/"+linkCode(c))
+		return
+	}
+
+	visitor := visitorOf(c)
+	if link.StickyVariants {
+		visitor.Variant, _ = c.Cookie(variantCookieName(link))
+	}
+
+	target, err := h.service.RedirectTarget(c.Request.Context(), link, visitor)
+	if err != nil {
+		log.Error("failed to apply utm presets", "link", link.ID, "error", err)
+	}
+
+	// HEAD requests come from link checkers, unfurlers and uptime probes.
+	// They see the same response but are not clicks, so they neither use
+	// up the click budget nor pin a variant.
+	if c.Request.Method != http.MethodHead {
+		if link.StickyVariants && target.Variant != "" && target.Variant != visitor.Variant {
+			c.SetCookie(variantCookieName(link), target.Variant, int(h.cfg.VariantCookieTTL.Seconds()), c.Request.URL.Path, "", isSecureRequest(c), true)
+		}
+
+		// A lost click must not break the redirect itself.
+		click := &model.Click{
+			Referrer:  c.Request.Referer(),
+			Host:      c.Request.Host,
+			Variant:   target.Variant,
+			Source:    clickSource(c),
+			UTMSource: c.Query("utm_source"),
+			UTMMedium: c.Query("utm_medium"),
+		}
+		if err := h.service.RecordClick(c.Request.Context(), link, visitor, click); err != nil {
+			log.Error("failed to record click", "error", err)
+		}
+	}
+
+	if target.AppURL != "" {
+		renderDeepLink(c, target.AppURL, target.URL)
+		return
+	}
+	c.Redirect(link.RedirectType, target.URL)
+}
+
+// @Summary Unlock link
+// @Description Submit the password of a protected short link
+// @Tags redirect
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param code path string true "Short code"
+// @Param password formData string true "Link password"
+// @Success 303
+// @Failure 401
+// @Failure 404
+// @Failure 429
+// @Router /{code} [post]
+func (h *Handler) UnlockLink(c *gin.Context) {
+	const op = "handler.unlockLink"
+	log := h.log.With(slog.String("op", op))
+
+	link, ok := h.resolveLink(c)
+	if !ok {
+		return
+	}
+
+	target := c.Request.URL.Path
+	if link.PasswordHash == "" {
+		c.Redirect(http.StatusSeeOther, target)
+		return
+	}
+
+	// Failures lock out a client of this link only, and the link as a whole
+	// once guesses come from too many addresses.
+	linkKey := strconv.FormatUint(uint64(link.ID), 10)
+	clientKey := linkKey + "|" + c.ClientIP()
+	if h.unlockLimiter.Blocked(clientKey) || h.linkLimiter.Blocked(linkKey) {
+		renderPassword(c, http.StatusTooManyRequests, target, "Too many failed attempts. Please try again later.")
+		return
+	}
+
+	token, err := h.service.UnlockLink(c.Request.Context(), link, c.PostForm("password"))
+	if err != nil {
+		if errors.IsErrorCode(err, errors.CodeInvalidPassword) {
+			h.unlockLimiter.RecordFailure(clientKey)
+			h.linkLimiter.RecordFailure(linkKey)
+			metrics.RecordError(c.Request.Context(), "link_unlock_failed", c.FullPath(), c.Request.Method)
+			renderPassword(c, http.StatusUnauthorized, target, "Incorrect password.")
+			return
+		}
+		log.Error("failed to unlock link", "error", err)
+		c.String(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	// The cookie is scoped to the whole host rather than the request path so
+	// that it covers both /code and the /code+ preview; its name already
+	// ties it to the link.
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(unlockCookieName(link), token, int(h.cfg.LinkUnlockTTL.Seconds()), "/", "", isSecureRequest(c), true)
+	c.Redirect(http.StatusSeeOther, target)
+}
+
+// @Summary Domain root
+// @Description Redirect the bare domain to its configured root URL
+// @Tags redirect
+// @Produce html
+// @Success 302
+// @Failure 404
+// @Router / [get]
+func (h *Handler) Root(c *gin.Context) {
+	domain, ok := h.requestDomain(c)
+	if !ok {
+		return
+	}
+
+	rootURL := h.cfg.RootURL
+	if domain != nil {
+		rootURL = domain.RootURL
+	}
+	if rootURL == "" {
+		renderNotFound(c)
+		return
+	}
+	c.Redirect(http.StatusFound, rootURL)
+}
+
+// @Summary robots.txt
+// @Description Serve robots.txt of the requested domain
+// @Tags redirect
+// @Produce plain
+// @Success 200
+// @Router /robots.txt [get]
+func (h *Handler) RobotsTxt(c *gin.Context) {
+	domain, ok := h.requestDomain(c)
+	if !ok {
+		return
+	}
+
+	robots := defaultRobotsTxt
+	if domain != nil && domain.RobotsTxt != "" {
+		robots = domain.RobotsTxt
+	}
+	c.String(http.StatusOK, robots)
+}
+
+// requestDomain resolves the custom domain of the request host; nil means the
+// default domain.
+func (h *Handler) requestDomain(c *gin.Context) (*model.Domain, bool) {
+	const op = "handler.requestDomain"
+	log := h.log.With(slog.String("op", op))
+
+	domain, err := h.service.ResolveDomain(c.Request.Context(), c.Request.Host)
+	if err != nil {
+		log.Error("failed to resolve domain", "error", err)
+		metrics.RecordError(c.Request.Context(), "redirect_error", c.FullPath(), c.Request.Method)
+		c.String(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return nil, false
+	}
+	return domain, true
+}
+
+// resolveLink looks up the link behind the :code parameter on the request
+// domain and renders the matching error page when it cannot be followed.
+func (h *Handler) resolveLink(c *gin.Context) (*model.Link, bool) {
+	const op = "handler.resolveLink"
+	log := h.log.With(slog.String("op", op))
+
+	domain, ok := h.requestDomain(c)
+	if !ok {
+		return nil, false
+	}
+
+	link, err := h.service.ResolveLink(c.Request.Context(), domain, linkCode(c))
+	if err == nil {
+		err = h.service.CheckLinkAvailable(c.Request.Context(), link)
+	}
+
+	switch {
+	case err == nil:
+		return link, true
+	case errors.IsErrorCode(err, errors.CodeDataNotFound):
+		if domain != nil && domain.NotFoundURL != "" {
+			c.Redirect(http.StatusFound, domain.NotFoundURL)
+			return nil, false
+		}
+		renderNotFound(c)
+	case errors.IsErrorCode(err, errors.CodeLinkGone):
+		renderGone(c, link.FallbackURL)
+	default:
+		log.Error("failed to resolve link", "error", err)
+		metrics.RecordError(c.Request.Context(), "redirect_error", c.FullPath(), c.Request.Method)
+		c.String(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+	return nil, false
+}
+
+// linkCode returns the short code of the request without the preview suffix.
+func linkCode(c *gin.Context) string {
+	return strings.TrimSuffix(c.Param("code"), previewSuffix)
+}
+
+func isPreviewRequest(c *gin.Context) bool {
+	return strings.HasSuffix(c.Param("code"), previewSuffix) || c.Query("preview") == "1"
+}
+
+func visitorOf(c *gin.Context) *model.Visitor {
+	return &model.Visitor{
+		IP:             net.ParseIP(c.ClientIP()),
+		UserAgent:      c.Request.UserAgent(),
+		AcceptLanguage: c.GetHeader("Accept-Language"),
+	}
+}
+
+// clickSource returns the source marker of a click, such as the src=qr added
+// to the URL encoded in QR codes.
+func clickSource(c *gin.Context) string {
+	if c.Query("src") == model.ClickSourceQR {
+		return model.ClickSourceQR
+	}
+	return ""
+}
+
+func unlockCookieName(link *model.Link) string {
+	return "via_unlock_" + strconv.FormatUint(uint64(link.ID), 10)
+}
+
+func variantCookieName(link *model.Link) string {
+	return "via_variant_" + strconv.FormatUint(uint64(link.ID), 10)
+}
+
+func isSecureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/config"
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"github.com/OxytocinGroup/theca-v3/internal/service"
+	errors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeService serves a single link on the default domain and counts clicks.
+type fakeService struct {
+	service.Service
+	link   *model.Link
+	clicks int
+}
+
+func (s *fakeService) ResolveDomain(context.Context, string) (*model.Domain, error) {
+	return nil, nil
+}
+
+func (s *fakeService) ResolveLink(context.Context, *model.Domain, string) (*model.Link, error) {
+	return s.link, nil
+}
+
+func (s *fakeService) CheckLinkAvailable(context.Context, *model.Link) error {
+	return nil
+}
+
+func (s *fakeService) RedirectTarget(_ context.Context, link *model.Link, _ *model.Visitor) (*model.RedirectTarget, error) {
+	return &model.RedirectTarget{URL: link.Destination, Variant: "b"}, nil
+}
+
+func (s *fakeService) RecordClick(context.Context, *model.Link, *model.Visitor, *model.Click) error {
+	s.clicks++
+	return nil
+}
+
+func (s *fakeService) UnlockLink(_ context.Context, _ *model.Link, password string) (string, error) {
+	if password != "secret" {
+		return "", errors.New(errors.CodeInvalidPassword, "неверный пароль")
+	}
+	return "token", nil
+}
+
+func newTestRouter(svc service.Service, cfg *config.Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewHandler(svc, slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		panic(err)
+	}
+	r.GET("/:code", h.Redirect)
+	r.HEAD("/:code", h.Redirect)
+	r.POST("/:code", h.UnlockLink)
+	return r
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		UnlockMaxFailures:  3,
+		UnlockLinkFailures: 100,
+		UnlockFailWindow:   time.Minute,
+		VariantCookieTTL:   time.Hour,
+		LinkUnlockTTL:      time.Hour,
+	}
+}
+
+func TestRedirectHeadDoesNotRecordClick(t *testing.T) {
+	svc := &fakeService{link: &model.Link{
+		ID:             1,
+		Destination:    "https://example.com/",
+		RedirectType:   http.StatusFound,
+		StickyVariants: true,
+	}}
+	r := newTestRouter(svc, testConfig())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/abc", nil))
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "https://example.com/" {
+		t.Fatalf("HEAD got %d to %q, want a redirect to the target", w.Code, w.Header().Get("Location"))
+	}
+	if len(w.Result().Cookies()) != 0 {
+		t.Fatalf("HEAD pinned a variant: %v", w.Result().Cookies())
+	}
+	if svc.clicks != 0 {
+		t.Fatalf("HEAD recorded %d clicks, want 0", svc.clicks)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/abc", nil))
+	if svc.clicks != 1 {
+		t.Fatalf("GET recorded %d clicks, want 1", svc.clicks)
+	}
+	if len(w.Result().Cookies()) != 1 {
+		t.Fatalf("GET set cookies %v, want the variant cookie", w.Result().Cookies())
+	}
+}
+
+func unlock(r *gin.Engine, path, ip, forwardedFor, password string) *httptest.ResponseRecorder {
+	form := url.Values{"password": {password}}
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.RemoteAddr = ip + ":1234"
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func protectedLink() *fakeService {
+	return &fakeService{link: &model.Link{ID: 7, Destination: "https://example.com/", PasswordHash: "hash"}}
+}
+
+func TestUnlockLockoutIgnoresSpoofedForwardedFor(t *testing.T) {
+	r := newTestRouter(protectedLink(), testConfig())
+
+	for i := range 3 {
+		w := unlock(r, "/abc", "203.0.113.1", "198.51.100."+strconv.Itoa(i+1), "wrong")
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d got %d, want 401", i, w.Code)
+		}
+	}
+	if w := unlock(r, "/abc", "203.0.113.1", "198.51.100.9", "secret"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got %d after spoofing X-Forwarded-For, want 429", w.Code)
+	}
+	if w := unlock(r, "/abc", "203.0.113.2", "", "secret"); w.Code != http.StatusSeeOther {
+		t.Fatalf("another client got %d, want 303", w.Code)
+	}
+}
+
+func TestUnlockTrustedProxy(t *testing.T) {
+	cfg := testConfig()
+	cfg.TrustedProxies = []string{"10.0.0.1"}
+	r := newTestRouter(protectedLink(), cfg)
+
+	for range 3 {
+		unlock(r, "/abc", "10.0.0.1", "203.0.113.1", "wrong")
+	}
+	if w := unlock(r, "/abc", "10.0.0.1", "203.0.113.1", "secret"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("locked out client got %d, want 429", w.Code)
+	}
+	if w := unlock(r, "/abc", "10.0.0.1", "203.0.113.2", "secret"); w.Code != http.StatusSeeOther {
+		t.Fatalf("another client behind the proxy got %d, want 303", w.Code)
+	}
+}
+
+func TestUnlockLinkLockout(t *testing.T) {
+	cfg := testConfig()
+	cfg.UnlockLinkFailures = 5
+	r := newTestRouter(protectedLink(), cfg)
+
+	for i := range 5 {
+		unlock(r, "/abc", "203.0.113."+strconv.Itoa(i+1), "", "wrong")
+	}
+	if w := unlock(r, "/abc", "203.0.113.200", "", "secret"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got %d once the link had too many failures, want 429", w.Code)
+	}
+}
+
+func TestUnlockCookieCoversPreview(t *testing.T) {
+	r := newTestRouter(protectedLink(), testConfig())
+
+	w := unlock(r, "/abc+", "203.0.113.1", "", "secret")
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/abc+" {
+		t.Fatalf("got %d to %q, want 303 back to the preview", w.Code, w.Header().Get("Location"))
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Path != "/" {
+		t.Fatalf("got cookies %v, want one unlock cookie for path /", cookies)
+	}
+}
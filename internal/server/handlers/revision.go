@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"github.com/OxytocinGroup/theca-v3/internal/metrics"
+	errors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary List link revisions
+// @Description List the revision history of a link, newest first
+// @Tags link
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Link ID"
+// @Success 200 {array} model.LinkRevision
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/links/{id}/revisions [get]
+func (h *Handler) ListLinkRevisions(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	revisions, err := h.service.ListLinkRevisions(c.Request.Context(), c.GetUint("userID"), id)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "link_revisions_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, revisions)
+}
+
+// @Summary Roll back link
+// @Description Restore the settings a link had at the given revision
+// @Tags link
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Link ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} model.Link
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/links/{id}/revisions/{rev}/rollback [post]
+func (h *Handler) RollbackLink(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	number, ok := parseIDParam(c, "rev")
+	if !ok {
+		return
+	}
+
+	link, err := h.service.RollbackLink(c.Request.Context(), c.GetUint("userID"), id, number)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "link_rollback_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, link)
+}
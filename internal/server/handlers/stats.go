@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"log/slog"
+
+	"github.com/OxytocinGroup/theca-v3/internal/metrics"
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	errors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Click summary
+// @Description Aggregate the clicks on the current user's links, narrowed by tags, campaign and period
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param tag query []string false "Only links carrying every given tag" collectionFormat(multi)
+// @Param campaign_id query int false "Only links of the campaign"
+// @Param broken query bool false "Only links whose destination is (or is not) flagged as broken"
+// @Param from query string false "Start of the period (RFC 3339, inclusive)"
+// @Param to query string false "End of the period (RFC 3339, exclusive)"
+// @Success 200 {object} model.ClickSummary
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Router /api/stats [get]
+func (h *Handler) ClickSummary(c *gin.Context) {
+	const op = "handler.ClickSummary"
+	log := h.log.With(slog.String("op", op))
+
+	var filter model.StatsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		log.Debug("binding query", "err", err, "filter", filter)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	summary, err := h.service.ClickSummary(c.Request.Context(), c.GetUint("userID"), filter)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "stats_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, summary)
+}
+
+// @Summary Click time series
+// @Description Count the clicks on the current user's links per hour, day, week or month, with empty buckets included
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param interval query string false "Bucket length, day by default" Enums(hour, day, week, month)
+// @Param tz query string false "IANA time zone bucket boundaries follow, UTC by default"
+// @Param from query string false "Start of the period (RFC 3339); the first bucket extends back to its boundary"
+// @Param to query string false "End of the period (RFC 3339, exclusive), now by default"
+// @Param tag query []string false "Only links carrying every given tag" collectionFormat(multi)
+// @Param campaign_id query int false "Only links of the campaign"
+// @Param broken query bool false "Only links whose destination is (or is not) flagged as broken"
+// @Success 200 {object} model.TimeSeries
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Router /api/stats/timeseries [get]
+func (h *Handler) ClickTimeSeries(c *gin.Context) {
+	const op = "handler.ClickTimeSeries"
+	log := h.log.With(slog.String("op", op))
+
+	var req model.TimeSeriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Debug("binding query", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	series, err := h.service.ClickTimeSeries(c.Request.Context(), c.GetUint("userID"), req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "stats_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, series)
+}
+
+// @Summary Link click time series
+// @Description Count the clicks on a link per hour, day, week or month, with empty buckets included
+// @Tags link
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Link ID"
+// @Param interval query string false "Bucket length, day by default" Enums(hour, day, week, month)
+// @Param tz query string false "IANA time zone bucket boundaries follow, UTC by default"
+// @Param from query string false "Start of the period (RFC 3339); the first bucket extends back to its boundary"
+// @Param to query string false "End of the period (RFC 3339, exclusive), now by default"
+// @Success 200 {object} model.TimeSeries
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/links/{id}/stats [get]
+func (h *Handler) LinkTimeSeries(c *gin.Context) {
+	const op = "handler.LinkTimeSeries"
+	log := h.log.With(slog.String("op", op))
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.TimeSeriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Debug("binding query", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	series, err := h.service.LinkTimeSeries(c.Request.Context(), c.GetUint("userID"), id, req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "stats_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, series)
+}
+
+// @Summary Unique visitors
+// @Description Estimate the distinct visitors across the current user's links over a range of UTC days; a visitor of several links counts once
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "First day (YYYY-MM-DD), 29 days before to by default"
+// @Param to query string false "Last day (YYYY-MM-DD, inclusive), today by default"
+// @Param tag query []string false "Only links carrying every given tag" collectionFormat(multi)
+// @Param campaign_id query int false "Only links of the campaign"
+// @Param broken query bool false "Only links whose destination is (or is not) flagged as broken"
+// @Success 200 {object} model.UniqueVisitors
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Router /api/stats/visitors [get]
+func (h *Handler) UniqueVisitors(c *gin.Context) {
+	const op = "handler.UniqueVisitors"
+	log := h.log.With(slog.String("op", op))
+
+	var req model.VisitorsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Debug("binding query", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	visitors, err := h.service.UniqueVisitors(c.Request.Context(), c.GetUint("userID"), req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "stats_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, visitors)
+}
+
+// @Summary Link unique visitors
+// @Description Estimate the distinct visitors of a link over a range of UTC days
+// @Tags link
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Link ID"
+// @Param from query string false "First day (YYYY-MM-DD), 29 days before to by default"
+// @Param to query string false "Last day (YYYY-MM-DD, inclusive), today by default"
+// @Success 200 {object} model.UniqueVisitors
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/links/{id}/visitors [get]
+func (h *Handler) LinkUniqueVisitors(c *gin.Context) {
+	const op = "handler.LinkUniqueVisitors"
+	log := h.log.With(slog.String("op", op))
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.VisitorsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Debug("binding query", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	visitors, err := h.service.LinkUniqueVisitors(c.Request.Context(), c.GetUint("userID"), id, req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "stats_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, visitors)
+}
+
+// @Summary Link referrers
+// @Description Break the clicks of a link down by referrer class and list its top referrer hosts. The utm_source of the short URL takes precedence over the Referer header.
+// @Tags link
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Link ID"
+// @Param limit query int false "Number of hosts to list (1-100, 10 by default)"
+// @Param from query string false "Start of the period (RFC 3339, inclusive)"
+// @Param to query string false "End of the period (RFC 3339, exclusive)"
+// @Success 200 {object} model.ReferrerBreakdown
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/links/{id}/referrers [get]
+func (h *Handler) LinkReferrers(c *gin.Context) {
+	const op = "handler.LinkReferrers"
+	log := h.log.With(slog.String("op", op))
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.ReferrersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Debug("binding query", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	breakdown, err := h.service.LinkReferrers(c.Request.Context(), c.GetUint("userID"), id, req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "stats_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, breakdown)
+}
+
+// @Summary Click agents
+// @Description Break the clicks on the current user's links down by browser, operating system and device
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param versions query bool false "Split browsers and operating systems by version"
+// @Param limit query int false "Number of browsers and operating systems to list (1-100, 10 by default)"
+// @Param tag query []string false "Only links carrying every given tag" collectionFormat(multi)
+// @Param campaign_id query int false "Only links of the campaign"
+// @Param broken query bool false "Only links whose destination is (or is not) flagged as broken"
+// @Param from query string false "Start of the period (RFC 3339, inclusive)"
+// @Param to query string false "End of the period (RFC 3339, exclusive)"
+// @Success 200 {object} model.AgentBreakdown
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Router /api/stats/agents [get]
+func (h *Handler) AgentBreakdown(c *gin.Context) {
+	const op = "handler.AgentBreakdown"
+	log := h.log.With(slog.String("op", op))
+
+	var req model.AgentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Debug("binding query", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	breakdown, err := h.service.AgentBreakdown(c.Request.Context(), c.GetUint("userID"), req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "stats_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, breakdown)
+}
+
+// @Summary Link agents
+// @Description Break the clicks of a link down by browser, operating system and device
+// @Tags link
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Link ID"
+// @Param versions query bool false "Split browsers and operating systems by version"
+// @Param limit query int false "Number of browsers and operating systems to list (1-100, 10 by default)"
+// @Param from query string false "Start of the period (RFC 3339, inclusive)"
+// @Param to query string false "End of the period (RFC 3339, exclusive)"
+// @Success 200 {object} model.AgentBreakdown
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/links/{id}/agents [get]
+func (h *Handler) LinkAgents(c *gin.Context) {
+	const op = "handler.LinkAgents"
+	log := h.log.With(slog.String("op", op))
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.AgentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Debug("binding query", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	breakdown, err := h.service.LinkAgents(c.Request.Context(), c.GetUint("userID"), id, req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "stats_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, breakdown)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"log/slog"
+
+	"github.com/OxytocinGroup/theca-v3/internal/metrics"
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	errors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create tag
+// @Description Create a tag to attach to links
+// @Tags tag
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param createTagRequest body model.CreateTagRequest true "Create tag request"
+// @Success 200 {object} model.Tag
+// @Failure 400 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Router /api/tags [post]
+func (h *Handler) CreateTag(c *gin.Context) {
+	const op = "handler.createTag"
+	log := h.log.With(slog.String("op", op))
+
+	var req model.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Debug("binding json", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	tag, err := h.service.CreateTag(c.Request.Context(), c.GetUint("userID"), req)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "tag_create_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, tag)
+}
+
+// @Summary List tags
+// @Description List tags of the current user with the number of links carrying each
+// @Tags tag
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Tag
+// @Failure 401 {object} errors.Error
+// @Router /api/tags [get]
+func (h *Handler) ListTags(c *gin.Context) {
+	tags, err := h.service.ListTags(c.Request.Context(), c.GetUint("userID"))
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, tags)
+}
+
+// @Summary Rename tag
+// @Description Rename a tag; links carrying it keep it under the new name
+// @Tags tag
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tag ID"
+// @Param updateTagRequest body model.UpdateTagRequest true "Update tag request"
+// @Success 200 {object} model.Tag
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Router /api/tags/{id} [patch]
+func (h *Handler) UpdateTag(c *gin.Context) {
+	const op = "handler.updateTag"
+	log := h.log.With(slog.String("op", op))
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Debug("binding json", "err", err, "req", req)
+		metrics.RecordError(c.Request.Context(), "validation_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, errors.New(errors.CodeInvalidRequest, "Неверный формат запроса"))
+		return
+	}
+
+	tag, err := h.service.UpdateTag(c.Request.Context(), c.GetUint("userID"), id, req)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, tag)
+}
+
+// @Summary Delete tag
+// @Description Delete a tag and take it off every link carrying it
+// @Tags tag
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tag ID"
+// @Success 200
+// @Failure 404 {object} errors.Error
+// @Router /api/tags/{id} [delete]
+func (h *Handler) DeleteTag(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTag(c.Request.Context(), c.GetUint("userID"), id); err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, "Tag deleted successfully")
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"github.com/OxytocinGroup/theca-v3/internal/metrics"
+	errors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary List trash
+// @Description List links and bookmarks in the trash of the current user
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.TrashResponse
+// @Failure 401 {object} errors.Error
+// @Router /api/trash [get]
+func (h *Handler) ListTrash(c *gin.Context) {
+	trash, err := h.service.ListTrash(c.Request.Context(), c.GetUint("userID"))
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "trash_list_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, trash)
+}
+
+// @Summary Restore link
+// @Description Restore a link from the trash
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Link ID"
+// @Success 200 {object} model.Link
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/trash/links/{id}/restore [post]
+func (h *Handler) RestoreLink(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	link, err := h.service.RestoreLink(c.Request.Context(), c.GetUint("userID"), id)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "link_restore_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, link)
+}
+
+// @Summary Purge link
+// @Description Permanently delete a link from the trash, releasing its code
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Link ID"
+// @Success 200
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/trash/links/{id} [delete]
+func (h *Handler) PurgeLink(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.PurgeLink(c.Request.Context(), c.GetUint("userID"), id); err != nil {
+		metrics.RecordError(c.Request.Context(), "link_purge_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, "Link purged successfully")
+}
+
+// @Summary Restore bookmark
+// @Description Restore a bookmark from the trash
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bookmark ID"
+// @Success 200 {object} model.Bookmark
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/trash/bookmarks/{id}/restore [post]
+func (h *Handler) RestoreBookmark(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	bookmark, err := h.service.RestoreBookmark(c.Request.Context(), c.GetUint("userID"), id)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "bookmark_restore_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, bookmark)
+}
+
+// @Summary Purge bookmark
+// @Description Permanently delete a bookmark from the trash
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bookmark ID"
+// @Success 200
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/trash/bookmarks/{id} [delete]
+func (h *Handler) PurgeBookmark(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.PurgeBookmark(c.Request.Context(), c.GetUint("userID"), id); err != nil {
+		metrics.RecordError(c.Request.Context(), "bookmark_purge_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, "Bookmark purged successfully")
+}
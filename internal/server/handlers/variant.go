@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"github.com/OxytocinGroup/theca-v3/internal/metrics"
+	errors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Variant stats
+// @Description Compare the clicks served by each A/B split variant of a link
+// @Tags link
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Link ID"
+// @Success 200 {array} model.VariantStats
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /api/links/{id}/variants/stats [get]
+func (h *Handler) VariantStats(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.VariantStats(c.Request.Context(), c.GetUint("userID"), id)
+	if err != nil {
+		metrics.RecordError(c.Request.Context(), "link_variant_stats_error", c.Request.URL.Path, c.Request.Method)
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	errors.RespondWithSuccess(c, stats)
+}
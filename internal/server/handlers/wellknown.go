@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary apple-app-site-association
+// @Description Serve the iOS universal links association of the requested custom domain
+// @Tags redirect
+// @Produce json
+// @Success 200
+// @Failure 404
+// @Router /.well-known/apple-app-site-association [get]
+func (h *Handler) AppleAppSiteAssociation(c *gin.Context) {
+	domain, ok := h.requestDomain(c)
+	if !ok {
+		return
+	}
+	if domain == nil || len(domain.IOSAppIDs) == 0 {
+		c.String(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"applinks": gin.H{
+			"apps": []string{},
+			"details": []gin.H{{
+				"appIDs":     []string(domain.IOSAppIDs),
+				"components": []gin.H{{"/": "/*"}},
+				"paths":      []string{"*"},
+			}},
+		},
+	})
+}
+
+// @Summary assetlinks.json
+// @Description Serve the Android app links statement of the requested custom domain
+// @Tags redirect
+// @Produce json
+// @Success 200
+// @Failure 404
+// @Router /.well-known/assetlinks.json [get]
+func (h *Handler) AssetLinks(c *gin.Context) {
+	domain, ok := h.requestDomain(c)
+	if !ok {
+		return
+	}
+	if domain == nil || domain.AndroidPackage == "" || len(domain.AndroidCertHashes) == 0 {
+		c.String(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, []gin.H{{
+		"relation": []string{"delegate_permission/common.handle_all_urls"},
+		"target": gin.H{
+			"namespace":                "android_app",
+			"package_name":             domain.AndroidPackage,
+			"sha256_cert_fingerprints": []string(domain.AndroidCertHashes),
+		},
+	}})
+}
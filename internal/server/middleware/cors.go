@@ -7,13 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func PublicCORS() gin.HandlerFunc {
-	var origins = make([]string, 0, 30)
-	origins = append(origins, []string{
-		"https://theca.oxytocingroup.com",
-		"http://localhost:3000",
-	}...)
-
+func PublicCORS(origins []string) gin.HandlerFunc {
 	return cors.New(cors.Config{
 		AllowOrigins:     origins,
 		AllowMethods:     []string{"GET,POST,PATCH,PUT,DELETE,OPTIONS"},
@@ -26,7 +26,12 @@ func MetricsMiddleware() gin.HandlerFunc {
 	}()
 
 	return func(c *gin.Context) {
-		path := c.Request.URL.Path
+		// Label by route template rather than raw path: every short code is a
+		// distinct URL and would otherwise blow up the metric cardinality.
+		path := c.FullPath()
+		if path == "" {
+			path = "unmatched"
+		}
 		method := c.Request.Method
 		startTime := time.Now()
 
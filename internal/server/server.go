@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"sync"
@@ -22,15 +23,21 @@ type Server struct {
 	privateRouter *gin.Engine
 }
 
-func New(cfg *config.Config, log *slog.Logger) *Server {
+// New sets up the public and private servers. Client IPs are only taken from
+// X-Forwarded-For and X-Real-IP when the request comes from one of the
+// trusted proxies; with none configured the peer address is used as is.
+func New(cfg *config.Config, log *slog.Logger) (*Server, error) {
 	gin.SetMode(gin.ReleaseMode)
 	if cfg.IsLocalRun {
 		gin.SetMode(gin.DebugMode)
 	}
 
 	publicRouter := gin.New()
+	if err := publicRouter.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
 	publicRouter.Use(gin.Recovery())
-	publicRouter.Use(middleware.PublicCORS())
+	publicRouter.Use(middleware.PublicCORS(cfg.CORSOrigins))
 	publicRouter.Use(middleware.MetricsMiddleware())
 	publicServer := &http.Server{
 		Addr:    cfg.PublicAddr,
@@ -38,8 +45,11 @@ func New(cfg *config.Config, log *slog.Logger) *Server {
 	}
 
 	privateRouter := gin.New()
+	if err := privateRouter.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
 	privateRouter.Use(gin.Recovery())
-	privateRouter.Use(middleware.PublicCORS())
+	privateRouter.Use(middleware.PublicCORS(cfg.CORSOrigins))
 	privateServer := &http.Server{
 		Addr:    cfg.SwaggerAddr,
 		Handler: privateRouter,
@@ -52,7 +62,7 @@ func New(cfg *config.Config, log *slog.Logger) *Server {
 		publicRouter:  publicRouter,
 		privateServer: privateServer,
 		privateRouter: privateRouter,
-	}
+	}, nil
 }
 
 func (s *Server) Start() {
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+)
+
+func (s *service) CreateBookmark(ctx context.Context, userID uint, req model.CreateBookmarkRequest) (*model.Bookmark, error) {
+	const op = "service.CreateBookmark"
+	log := s.log.With("op", op)
+
+	bookmark := model.Bookmark{
+		Title:    req.Title,
+		URL:      req.URL,
+		IconURL:  req.IconURL,
+		UserID:   userID,
+		ShowText: req.ShowText,
+		// Empty fields are filled in from the page by FetchMetadata.
+		MetadataPending: req.Title == "" || req.IconURL == "",
+	}
+	if err := s.repo.CreateBookmark(ctx, &bookmark); err != nil {
+		return nil, err
+	}
+
+	log.Debug("bookmark created", "bookmark", bookmark.ID, "user", userID)
+	return &bookmark, nil
+}
+
+// ListBookmarks returns a page of the user's bookmarks and the cursor of the
+// next page, empty on the last one.
+func (s *service) ListBookmarks(ctx context.Context, userID uint, page model.PageRequest) ([]model.Bookmark, string, error) {
+	page.Q = strings.TrimSpace(page.Q)
+	return s.repo.ListUserBookmarks(ctx, userID, page)
+}
+
+// DeleteBookmark moves a bookmark to the trash.
+func (s *service) DeleteBookmark(ctx context.Context, userID, id uint) error {
+	const op = "service.DeleteBookmark"
+	log := s.log.With("op", op)
+
+	bookmark, err := s.repo.GetUserBookmark(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteBookmark(ctx, bookmark); err != nil {
+		return err
+	}
+
+	log.Debug("bookmark moved to trash", "bookmark", bookmark.ID, "user", userID)
+	return nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+)
+
+func (s *service) CreateCampaign(ctx context.Context, userID uint, req model.CreateCampaignRequest) (*model.Campaign, error) {
+	const op = "service.CreateCampaign"
+	log := s.log.With("op", op)
+
+	campaign := model.Campaign{
+		Name:   req.Name,
+		UTM:    normalizeUTM(req.UTM),
+		UserID: userID,
+	}
+	if err := s.repo.CreateCampaign(ctx, &campaign); err != nil {
+		return nil, err
+	}
+
+	log.Debug("campaign created", "campaign", campaign.ID, "user", userID)
+	return &campaign, nil
+}
+
+func (s *service) GetCampaign(ctx context.Context, userID, id uint) (*model.Campaign, error) {
+	return s.repo.GetUserCampaign(ctx, userID, id)
+}
+
+func (s *service) ListCampaigns(ctx context.Context, userID uint) ([]model.Campaign, error) {
+	return s.repo.ListUserCampaigns(ctx, userID)
+}
+
+func (s *service) UpdateCampaign(ctx context.Context, userID, id uint, req model.UpdateCampaignRequest) (*model.Campaign, error) {
+	campaign, err := s.repo.GetUserCampaign(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		campaign.Name = *req.Name
+	}
+	if req.UTM != nil {
+		campaign.UTM = normalizeUTM(*req.UTM)
+	}
+
+	if err := s.repo.SaveCampaign(ctx, campaign); err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+// DeleteCampaign removes a campaign; its links are kept and detached.
+func (s *service) DeleteCampaign(ctx context.Context, userID, id uint) error {
+	campaign, err := s.repo.GetUserCampaign(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteCampaign(ctx, campaign)
+}
+
+// CampaignStats aggregates the clicks across all links of a campaign.
+func (s *service) CampaignStats(ctx context.Context, userID, id uint, filter model.StatsFilter) (*model.ClickSummary, error) {
+	campaign, err := s.repo.GetUserCampaign(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.CampaignID = &campaign.ID
+	return s.ClickSummary(ctx, userID, filter)
+}
+
+func (s *service) userCampaignID(ctx context.Context, userID, campaignID uint) (uint, error) {
+	if campaignID == 0 {
+		return 0, nil
+	}
+
+	campaign, err := s.repo.GetUserCampaign(ctx, userID, campaignID)
+	if err != nil {
+		return 0, err
+	}
+	return campaign.ID, nil
+}
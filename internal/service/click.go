@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+)
+
+// ClickQueue buffers click events for writing, such as worker.Batch. Add
+// reports whether the click was accepted; the queue accounts for drops.
+type ClickQueue interface {
+	Add(click model.Click) bool
+}
+
+// RecordClick completes a click with the visitor details and queues it. The
+// link's click count catches up once the click is written.
+func (s *service) RecordClick(ctx context.Context, link *model.Link, visitor *model.Visitor, click *model.Click) error {
+	const op = "service.RecordClick"
+	log := s.log.With("op", op)
+
+	click.LinkID = link.ID
+	click.CreatedAt = time.Now().UTC()
+	click.UserAgent = truncate(visitor.UserAgent, 512)
+	click.Referrer = truncate(click.Referrer, 2048)
+	click.Host = truncate(NormalizeHost(click.Host), 255)
+	s.classifyReferrer(click)
+
+	agent := s.agents.Parse(visitor.UserAgent)
+	click.Browser, click.BrowserVersion = truncate(agent.Browser, 32), agent.BrowserVersion
+	click.OS, click.OSVersion = truncate(agent.OS, 32), agent.OSVersion
+	click.Device = agent.Device
+
+	hash, err := s.visitorHash(visitor, click.CreatedAt)
+	if err != nil {
+		// The click still counts, just not towards unique visitors.
+		log.Warn("failed to hash visitor", "error", err)
+	}
+	click.IPHash = hash
+
+	if s.clicks == nil {
+		return s.repo.CreateClicks(ctx, []model.Click{*click})
+	}
+	s.clicks.Add(*click)
+	return nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+const (
+	CodeStrategyRandom   = "random"
+	CodeStrategySequence = "sequence"
+	CodeStrategyReadable = "readable"
+
+	base62Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	// readableAlphabet drops characters that are easy to confuse when a code
+	// is read aloud or retyped: 0/O/o, 1/l/I and the upper case set entirely.
+	readableAlphabet = "23456789abcdefghijkmnpqrstuvwxyz"
+)
+
+// CodeGenerator produces candidate short codes for new links.
+type CodeGenerator interface {
+	// Generate returns a candidate code for the link with the given primary
+	// key. attempt starts at 0 and grows on every collision so deterministic
+	// generators can derive a different code on retry.
+	Generate(id uint, attempt int) (string, error)
+	// NeedsID reports whether Generate depends on the link primary key, in
+	// which case the link is inserted before its code is assigned.
+	NeedsID() bool
+}
+
+// NewCodeGenerator builds the generator for the given strategy. length is the
+// exact code length for random strategies and the minimum one for sequence.
+// The sequence strategy needs a salt, without which codes would reveal the
+// link IDs to anyone who knows the scheme.
+func NewCodeGenerator(strategy string, length int, salt string) (CodeGenerator, error) {
+	if length <= 0 {
+		return nil, fmt.Errorf("invalid code length %d", length)
+	}
+
+	switch strategy {
+	case CodeStrategyRandom:
+		return &randomGenerator{alphabet: base62Alphabet, length: length}, nil
+	case CodeStrategyReadable:
+		return &randomGenerator{alphabet: readableAlphabet, length: length}, nil
+	case CodeStrategySequence:
+		if salt == "" {
+			return nil, fmt.Errorf("code strategy %q needs a salt", strategy)
+		}
+		alphabet := []byte(base62Alphabet)
+		consistentShuffle(alphabet, salt)
+		return &sequenceGenerator{alphabet: alphabet, minLength: length}, nil
+	default:
+		return nil, fmt.Errorf("unknown code strategy %q", strategy)
+	}
+}
+
+type randomGenerator struct {
+	alphabet string
+	length   int
+}
+
+func (g *randomGenerator) Generate(_ uint, _ int) (string, error) {
+	alphabetLen := big.NewInt(int64(len(g.alphabet)))
+	code := make([]byte, g.length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetLen)
+		if err != nil {
+			return "", err
+		}
+		code[i] = g.alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+func (g *randomGenerator) NeedsID() bool {
+	return false
+}
+
+// sequenceGenerator encodes the link primary key in the style of Sqids: the
+// alphabet is rotated by an offset derived from the number itself, so
+// consecutive IDs do not produce consecutive codes, and the result is padded
+// up to minLength.
+type sequenceGenerator struct {
+	alphabet  []byte
+	minLength int
+}
+
+func (g *sequenceGenerator) Generate(id uint, attempt int) (string, error) {
+	n := uint64(id)
+	size := len(g.alphabet)
+
+	offset := (int(g.alphabet[n%uint64(size)]) + attempt) % size
+	alphabet := make([]byte, 0, size)
+	alphabet = append(alphabet, g.alphabet[offset:]...)
+	alphabet = append(alphabet, g.alphabet[:offset]...)
+
+	code := []byte{alphabet[0]}
+	reverse(alphabet)
+	code = append(code, toBase(n, alphabet[1:])...)
+
+	if len(code) < g.minLength {
+		code = append(code, alphabet[0])
+		for len(code) < g.minLength {
+			shuffle(alphabet)
+			code = append(code, alphabet[:min(g.minLength-len(code), size)]...)
+		}
+	}
+
+	return string(code), nil
+}
+
+func (g *sequenceGenerator) NeedsID() bool {
+	return true
+}
+
+func toBase(n uint64, alphabet []byte) []byte {
+	size := uint64(len(alphabet))
+	var digits []byte
+	for {
+		digits = append([]byte{alphabet[n%size]}, digits...)
+		n /= size
+		if n == 0 {
+			return digits
+		}
+	}
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+// shuffle is the deterministic shuffle used by Sqids.
+func shuffle(b []byte) {
+	for i, j := 0, len(b)-1; j > 0; i, j = i+1, j-1 {
+		r := (i*j + int(b[i]) + int(b[j])) % len(b)
+		b[i], b[r] = b[r], b[i]
+	}
+}
+
+// consistentShuffle permutes the alphabet by salt the way Hashids does, so
+// that installations with different salts produce different codes.
+func consistentShuffle(b []byte, salt string) {
+	if salt == "" {
+		return
+	}
+	for i, v, p := len(b)-1, 0, 0; i > 0; i-- {
+		v %= len(salt)
+		n := int(salt[v])
+		p += n
+		j := (n + v + p) % i
+		b[i], b[j] = b[j], b[i]
+		v++
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/OxytocinGroup/theca-v3/internal/config"
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"github.com/OxytocinGroup/theca-v3/internal/repository"
+)
+
+func TestSequenceGeneratorUnique(t *testing.T) {
+	gen, err := NewCodeGenerator(CodeStrategySequence, 6, "via")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]uint, 100000)
+	for id := uint(1); id <= 100000; id++ {
+		code, err := gen.Generate(id, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prev, ok := seen[code]; ok {
+			t.Fatalf("ids %d and %d both got code %q", prev, id, code)
+		}
+		seen[code] = id
+
+		if len(code) < 6 {
+			t.Fatalf("code %q of id %d is shorter than the minimum length", code, id)
+		}
+		assertAlphabet(t, code, base62Alphabet)
+	}
+}
+
+func TestSequenceGeneratorRetryChangesCode(t *testing.T) {
+	gen, err := NewCodeGenerator(CodeStrategySequence, 6, "via")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]bool)
+	for attempt := range 5 {
+		code, err := gen.Generate(42, attempt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[code] {
+			t.Fatalf("attempt %d repeated code %q", attempt, code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestSequenceGeneratorSalt(t *testing.T) {
+	a, err := NewCodeGenerator(CodeStrategySequence, 6, "via")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewCodeGenerator(CodeStrategySequence, 6, "another salt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	same := 0
+	for id := uint(1); id <= 100; id++ {
+		codeA, _ := a.Generate(id, 0)
+		codeB, _ := b.Generate(id, 0)
+		if codeA == codeB {
+			same++
+		}
+	}
+	if same > 0 {
+		t.Fatalf("%d of 100 codes are the same under different salts", same)
+	}
+}
+
+func TestRandomGenerator(t *testing.T) {
+	tests := []struct {
+		strategy string
+		alphabet string
+	}{
+		{CodeStrategyRandom, base62Alphabet},
+		{CodeStrategyReadable, readableAlphabet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			gen, err := NewCodeGenerator(tt.strategy, 8, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gen.NeedsID() {
+				t.Fatal("random generators must not need the link id")
+			}
+			for range 1000 {
+				code, err := gen.Generate(0, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(code) != 8 {
+					t.Fatalf("code %q is not 8 characters long", code)
+				}
+				assertAlphabet(t, code, tt.alphabet)
+			}
+		})
+	}
+}
+
+func TestReadableAlphabetHasNoLookalikes(t *testing.T) {
+	if strings.ContainsAny(readableAlphabet, "0Oo1lIABCDEFGHIJKLMNOPQRSTUVWXYZ") {
+		t.Fatalf("readable alphabet %q contains a confusable character", readableAlphabet)
+	}
+}
+
+func TestNewCodeGeneratorInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		length   int
+		salt     string
+	}{
+		{"unknown strategy", "uuid", 7, "via"},
+		{"zero length", CodeStrategyRandom, 0, "via"},
+		{"negative length", CodeStrategySequence, -1, "via"},
+		{"sequence without salt", CodeStrategySequence, 7, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCodeGenerator(tt.strategy, tt.length, tt.salt); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestNewServiceRejectsInvalidCodeConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Config)
+	}{
+		{"unknown strategy", func(cfg *config.Config) { cfg.CodeStrategy = "uuid" }},
+		{"sequence without salt", func(cfg *config.Config) { cfg.CodeStrategy, cfg.CodeSalt = CodeStrategySequence, "" }},
+		{"zero length", func(cfg *config.Config) { cfg.CodeLength = 0 }},
+		{"zero attempts", func(cfg *config.Config) { cfg.CodeMaxAttempts = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			tt.modify(cfg)
+			if _, err := NewService(nil, discardLogger(), cfg); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+
+	if _, err := NewService(nil, discardLogger(), testConfig()); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+}
+
+// takenCodes reports every code as taken, or only the listed ones.
+type takenCodes struct {
+	repository.Repository
+	taken  map[string]bool
+	checks int
+}
+
+func (r *takenCodes) LinkCodeExists(_ context.Context, _ uint, code string) (bool, error) {
+	r.checks++
+	return r.taken == nil || r.taken[code], nil
+}
+
+// fixedCodes hands out the given codes in order.
+type fixedCodes []string
+
+func (g fixedCodes) Generate(_ uint, attempt int) (string, error) {
+	return g[attempt%len(g)], nil
+}
+
+func (g fixedCodes) NeedsID() bool {
+	return false
+}
+
+func TestGenerateCodeRetryCap(t *testing.T) {
+	cfg := testConfig()
+	cfg.CodeMaxAttempts = 3
+	s := &service{cfg: cfg, codes: fixedCodes{"a", "b", "c", "d"}, reserved: map[string]struct{}{}}
+
+	repo := &takenCodes{}
+	if _, err := s.generateCode(context.Background(), repo, &model.Link{}); err == nil {
+		t.Fatal("expected an error once every attempt collides")
+	}
+	if repo.checks != 3 {
+		t.Fatalf("checked %d codes, want 3", repo.checks)
+	}
+}
+
+func TestGenerateCodeSkipsTakenAndReserved(t *testing.T) {
+	s := &service{
+		cfg:      testConfig(),
+		codes:    fixedCodes{"api", "taken", "free"},
+		reserved: map[string]struct{}{"api": {}},
+	}
+
+	repo := &takenCodes{taken: map[string]bool{"taken": true}}
+	code, err := s.generateCode(context.Background(), repo, &model.Link{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "free" {
+		t.Fatalf("got code %q, want %q", code, "free")
+	}
+	if repo.checks != 2 {
+		t.Fatalf("checked %d codes, want 2; reserved codes must not reach the database", repo.checks)
+	}
+}
+
+func assertAlphabet(t *testing.T, code, alphabet string) {
+	t.Helper()
+	for _, c := range code {
+		if !strings.ContainsRune(alphabet, c) {
+			t.Fatalf("code %q has %q outside the alphabet", code, c)
+		}
+	}
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		CodeStrategy:    CodeStrategyRandom,
+		CodeSalt:        "via",
+		CodeLength:      7,
+		CodeMaxAttempts: 5,
+	}
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/useragent"
+)
+
+var (
+	iosAppIDPattern       = regexp.MustCompile(`^[A-Z0-9]{10}\.[A-Za-z0-9][A-Za-z0-9.-]*$`)
+	androidPackagePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z][A-Za-z0-9_]*)+$`)
+	certHashPattern       = regexp.MustCompile(`^([0-9A-F]{2}:){31}[0-9A-F]{2}$`)
+)
+
+// unsafeAppSchemes can run code in the browser and are never accepted as
+// app URLs.
+var unsafeAppSchemes = map[string]struct{}{
+	"javascript": {}, "data": {}, "vbscript": {}, "file": {}, "blob": {}, "about": {},
+}
+
+// applyDeepLink sends iOS and Android visitors to the app of the link. The
+// target computed for the web stays as the fallback unless a store URL is
+// set. Universal and app links are plain https URLs the OS hands to the app,
+// so they are redirected to directly.
+func applyDeepLink(target *model.RedirectTarget, deep model.DeepLink, traits visitorTraits) {
+	if traits.device == useragent.DeviceBot {
+		return
+	}
+
+	var appURL, storeURL string
+	switch traits.os {
+	case useragent.OSIOS:
+		appURL, storeURL = deep.IOSURL, deep.IOSStoreURL
+	case useragent.OSAndroid:
+		appURL, storeURL = deep.AndroidURL, deep.AndroidStoreURL
+	}
+	if appURL == "" {
+		return
+	}
+
+	// The visitor leaves for the app, so no A/B variant is served.
+	target.Variant = ""
+	if isWebURL(appURL) {
+		target.URL = appURL
+		return
+	}
+	if storeURL != "" {
+		target.URL = storeURL
+	}
+	target.AppURL = withBrowserFallback(appURL, target.URL)
+}
+
+// withBrowserFallback lets Chrome handle a missing app itself by adding
+// S.browser_fallback_url to intent: URLs that do not carry one.
+func withBrowserFallback(appURL, fallback string) string {
+	if !strings.HasPrefix(strings.ToLower(appURL), "intent:") || strings.Contains(appURL, "S.browser_fallback_url=") {
+		return appURL
+	}
+	i := strings.LastIndex(appURL, ";end")
+	if i < 0 {
+		return appURL
+	}
+	return appURL[:i] + ";S.browser_fallback_url=" + url.QueryEscape(fallback) + appURL[i:]
+}
+
+func isWebURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+func validateDeepLink(deep model.DeepLink) (model.DeepLink, error) {
+	deep = model.DeepLink{
+		IOSURL:          strings.TrimSpace(deep.IOSURL),
+		IOSStoreURL:     strings.TrimSpace(deep.IOSStoreURL),
+		AndroidURL:      strings.TrimSpace(deep.AndroidURL),
+		AndroidStoreURL: strings.TrimSpace(deep.AndroidStoreURL),
+	}
+
+	for _, appURL := range []string{deep.IOSURL, deep.AndroidURL} {
+		if appURL == "" {
+			continue
+		}
+		if err := validateAppURL(appURL); err != nil {
+			return deep, err
+		}
+	}
+	for _, storeURL := range []string{deep.IOSStoreURL, deep.AndroidStoreURL} {
+		if storeURL == "" {
+			continue
+		}
+		if err := validateDestination(storeURL); err != nil {
+			return deep, err
+		}
+	}
+	return deep, nil
+}
+
+func validateAppURL(raw string) error {
+	invalid := customerrors.New(customerrors.CodeDataInvalid, "Неверная ссылка на приложение")
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return invalid
+	}
+	if _, ok := unsafeAppSchemes[strings.ToLower(u.Scheme)]; ok {
+		return invalid
+	}
+	if isWebURL(raw) && u.Host == "" {
+		return invalid
+	}
+	return nil
+}
+
+// normalizeAppAssociation validates the app identifiers a domain publishes
+// for universal links and Android app links.
+func normalizeAppAssociation(appIDs []string, androidPackage string, certHashes []string) ([]string, string, []string, error) {
+	ids := make([]string, 0, len(appIDs))
+	for _, id := range appIDs {
+		id = strings.TrimSpace(id)
+		if !iosAppIDPattern.MatchString(id) {
+			return nil, "", nil, customerrors.New(customerrors.CodeDataInvalid, "Неверный идентификатор iOS приложения: "+id)
+		}
+		ids = append(ids, id)
+	}
+
+	androidPackage = strings.TrimSpace(androidPackage)
+	if androidPackage != "" && !androidPackagePattern.MatchString(androidPackage) {
+		return nil, "", nil, customerrors.New(customerrors.CodeDataInvalid, "Неверное имя Android пакета")
+	}
+
+	hashes := make([]string, 0, len(certHashes))
+	for _, h := range certHashes {
+		h = strings.ToUpper(strings.TrimSpace(h))
+		if !certHashPattern.MatchString(h) {
+			return nil, "", nil, customerrors.New(customerrors.CodeDataInvalid, "Неверный SHA-256 отпечаток сертификата")
+		}
+		hashes = append(hashes, h)
+	}
+	if len(hashes) > 0 && androidPackage == "" {
+		return nil, "", nil, customerrors.New(customerrors.CodeDataInvalid, "Для отпечатков сертификата нужно имя Android пакета")
+	}
+
+	return ids, androidPackage, hashes, nil
+}
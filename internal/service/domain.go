@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+)
+
+// Resolver looks up DNS TXT records for domain verification. *net.Resolver
+// satisfies it; tests can pass a stub through WithResolver.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// newResolver returns the system resolver, or one that sends every query to
+// addr when it is set.
+func newResolver(addr string) Resolver {
+	if addr == "" {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+}
+
+func (s *service) CreateDomain(ctx context.Context, userID uint, req model.CreateDomainRequest) (*model.Domain, error) {
+	const op = "service.CreateDomain"
+	log := s.log.With("op", op)
+
+	host := NormalizeHost(req.Host)
+	if host == NormalizeHost(s.cfg.DefaultDomain) {
+		return nil, customerrors.New(customerrors.CodeDataConflict, "Этот домен используется сервисом по умолчанию")
+	}
+	if err := validateDomainURLs(req.RootURL, req.NotFoundURL); err != nil {
+		return nil, err
+	}
+	appIDs, androidPackage, certHashes, err := normalizeAppAssociation(req.IOSAppIDs, req.AndroidPackage, req.AndroidCertHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		log.Error("failed to generate verification token", "error", err)
+		return nil, err
+	}
+
+	domain := model.Domain{
+		Host:              host,
+		VerificationToken: hex.EncodeToString(token),
+		RootURL:           req.RootURL,
+		NotFoundURL:       req.NotFoundURL,
+		RobotsTxt:         req.RobotsTxt,
+		AndroidPackage:    androidPackage,
+		IOSAppIDs:         appIDs,
+		AndroidCertHashes: certHashes,
+		UserID:            userID,
+	}
+	if err := s.repo.CreateDomain(ctx, &domain); err != nil {
+		return nil, err
+	}
+
+	log.Debug("domain created", "domain", domain.ID, "user", userID)
+	return &domain, nil
+}
+
+func (s *service) GetDomain(ctx context.Context, userID, id uint) (*model.Domain, error) {
+	return s.repo.GetUserDomain(ctx, userID, id)
+}
+
+func (s *service) ListDomains(ctx context.Context, userID uint) ([]model.Domain, error) {
+	return s.repo.ListUserDomains(ctx, userID)
+}
+
+func (s *service) UpdateDomain(ctx context.Context, userID, id uint, req model.UpdateDomainRequest) (*model.Domain, error) {
+	domain, err := s.repo.GetUserDomain(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RootURL != nil {
+		domain.RootURL = *req.RootURL
+	}
+	if req.NotFoundURL != nil {
+		domain.NotFoundURL = *req.NotFoundURL
+	}
+	if req.RobotsTxt != nil {
+		domain.RobotsTxt = *req.RobotsTxt
+	}
+	if err := validateDomainURLs(domain.RootURL, domain.NotFoundURL); err != nil {
+		return nil, err
+	}
+
+	appIDs, androidPackage, certHashes := []string(domain.IOSAppIDs), domain.AndroidPackage, []string(domain.AndroidCertHashes)
+	if req.IOSAppIDs != nil {
+		appIDs = *req.IOSAppIDs
+	}
+	if req.AndroidPackage != nil {
+		androidPackage = *req.AndroidPackage
+	}
+	if req.AndroidCertHashes != nil {
+		certHashes = *req.AndroidCertHashes
+	}
+	appIDs, androidPackage, certHashes, err = normalizeAppAssociation(appIDs, androidPackage, certHashes)
+	if err != nil {
+		return nil, err
+	}
+	domain.IOSAppIDs, domain.AndroidPackage, domain.AndroidCertHashes = appIDs, androidPackage, certHashes
+
+	if err := s.repo.SaveDomain(ctx, domain); err != nil {
+		return nil, err
+	}
+	return domain, nil
+}
+
+func (s *service) DeleteDomain(ctx context.Context, userID, id uint) error {
+	domain, err := s.repo.GetUserDomain(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	links, err := s.repo.CountDomainLinks(ctx, domain.ID)
+	if err != nil {
+		return err
+	}
+	if links > 0 {
+		return customerrors.New(customerrors.CodeDataConflict, "К домену привязаны ссылки")
+	}
+
+	return s.repo.DeleteDomain(ctx, domain)
+}
+
+// VerifyDomain checks the DNS TXT challenge of a domain and marks it
+// verified, taking the host over from any domain verified before. Only
+// verified domains take part in host-based resolution.
+func (s *service) VerifyDomain(ctx context.Context, userID, id uint) (*model.Domain, error) {
+	const op = "service.VerifyDomain"
+	log := s.log.With("op", op)
+
+	domain, err := s.repo.GetUserDomain(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if domain.Verified() {
+		return domain, nil
+	}
+
+	records, err := s.resolver.LookupTXT(ctx, domain.ChallengeName)
+	if err != nil {
+		log.Debug("txt lookup failed", "host", domain.Host, "error", err)
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Не удалось получить TXT запись домена")
+	}
+
+	found := false
+	for _, record := range records {
+		if strings.TrimSpace(record) == domain.ChallengeValue {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "TXT запись для подтверждения домена не найдена")
+	}
+
+	now := time.Now()
+	domain.VerifiedAt = &now
+	if err := s.repo.VerifyDomain(ctx, domain); err != nil {
+		return nil, err
+	}
+
+	log.Info("domain verified", "domain", domain.ID, "host", domain.Host)
+	return domain, nil
+}
+
+// ResolveDomain maps a request host to a verified custom domain. It returns
+// nil for the default domain and for hosts that are unknown or unverified,
+// which are all served from the default link namespace.
+func (s *service) ResolveDomain(ctx context.Context, host string) (*model.Domain, error) {
+	host = NormalizeHost(host)
+	if host == "" || host == NormalizeHost(s.cfg.DefaultDomain) {
+		return nil, nil
+	}
+
+	domain, err := s.repo.GetDomainByHost(ctx, host)
+	if err != nil {
+		if customerrors.IsErrorCode(err, customerrors.CodeDataNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !domain.Verified() {
+		return nil, nil
+	}
+	return domain, nil
+}
+
+// ReapDomainClaims deletes domains that were never verified, or lost their
+// host, within the claim TTL, so that an abandoned claim does not linger.
+func (s *service) ReapDomainClaims(ctx context.Context) error {
+	const op = "service.ReapDomainClaims"
+	log := s.log.With("op", op)
+
+	deleted, err := s.repo.DeleteStaleDomainClaims(ctx, time.Now().Add(-s.cfg.DomainClaimTTL))
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		log.Info("deleted stale domain claims", "domains", deleted)
+	}
+	return nil
+}
+
+// userDomainID checks that the user may create links on the given domain.
+func (s *service) userDomainID(ctx context.Context, userID, domainID uint) (uint, error) {
+	if domainID == 0 {
+		return 0, nil
+	}
+
+	domain, err := s.repo.GetUserDomain(ctx, userID, domainID)
+	if err != nil {
+		return 0, err
+	}
+	if !domain.Verified() {
+		return 0, customerrors.New(customerrors.CodeForbidden, "Домен ещё не подтверждён")
+	}
+	return domain.ID, nil
+}
+
+// NormalizeHost lowercases a host and strips the port and trailing dot.
+func NormalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+func validateDomainURLs(urls ...string) error {
+	for _, u := range urls {
+		if u == "" {
+			continue
+		}
+		if err := validateDestination(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"github.com/OxytocinGroup/theca-v3/internal/repository"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+)
+
+// stubResolver answers TXT lookups from a fixed table.
+type stubResolver map[string][]string
+
+func (r stubResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	records, ok := r[name]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return records, nil
+}
+
+// domainRepo holds domains in memory, keyed by id.
+type domainRepo struct {
+	repository.Repository
+	domains  map[uint]*model.Domain
+	verified []uint
+}
+
+func (r *domainRepo) GetUserDomain(_ context.Context, userID, id uint) (*model.Domain, error) {
+	d, ok := r.domains[id]
+	if !ok || d.UserID != userID {
+		return nil, customerrors.New(customerrors.CodeDataNotFound, "Домен не найден")
+	}
+	d.AfterFind(nil)
+	return d, nil
+}
+
+func (r *domainRepo) GetDomainByHost(_ context.Context, host string) (*model.Domain, error) {
+	for _, d := range r.domains {
+		if d.Host == host && d.Verified() {
+			return d, nil
+		}
+	}
+	return nil, customerrors.New(customerrors.CodeDataNotFound, "Домен не найден")
+}
+
+func (r *domainRepo) VerifyDomain(_ context.Context, domain *model.Domain) error {
+	r.verified = append(r.verified, domain.ID)
+	r.domains[domain.ID] = domain
+	return nil
+}
+
+func newDomainService(t *testing.T, repo repository.Repository, resolver Resolver) Service {
+	t.Helper()
+	cfg := testConfig()
+	cfg.DefaultDomain = "via.example.com"
+	s, err := NewService(repo, discardLogger(), cfg, WithResolver(resolver))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestVerifyDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver stubResolver
+		wantErr  bool
+	}{
+		{"challenge published", stubResolver{"_via-challenge.go.example.org": {"other", " via-verification=token "}}, false},
+		{"wrong value", stubResolver{"_via-challenge.go.example.org": {"via-verification=another"}}, true},
+		{"record on another host", stubResolver{"_via-challenge.example.org": {"via-verification=token"}}, true},
+		{"lookup fails", stubResolver{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &domainRepo{domains: map[uint]*model.Domain{
+				1: {ID: 1, UserID: 10, Host: "go.example.org", VerificationToken: "token"},
+			}}
+			s := newDomainService(t, repo, tt.resolver)
+
+			domain, err := s.VerifyDomain(context.Background(), 10, 1)
+			if tt.wantErr {
+				if !customerrors.IsErrorCode(err, customerrors.CodeDataInvalid) {
+					t.Fatalf("got error %v, want %s", err, customerrors.CodeDataInvalid)
+				}
+				if len(repo.verified) != 0 || repo.domains[1].Verified() {
+					t.Fatal("domain was verified without its challenge")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !domain.Verified() || len(repo.verified) != 1 {
+				t.Fatal("domain was not verified")
+			}
+		})
+	}
+}
+
+func TestVerifyDomainOfAnotherUser(t *testing.T) {
+	repo := &domainRepo{domains: map[uint]*model.Domain{
+		1: {ID: 1, UserID: 10, Host: "go.example.org", VerificationToken: "token"},
+	}}
+	s := newDomainService(t, repo, stubResolver{"_via-challenge.go.example.org": {"via-verification=token"}})
+
+	if _, err := s.VerifyDomain(context.Background(), 11, 1); !customerrors.IsErrorCode(err, customerrors.CodeDataNotFound) {
+		t.Fatalf("got error %v, want %s", err, customerrors.CodeDataNotFound)
+	}
+}
+
+func TestResolveDomain(t *testing.T) {
+	now := time.Now()
+	repo := &domainRepo{domains: map[uint]*model.Domain{
+		1: {ID: 1, UserID: 10, Host: "go.example.org", VerifiedAt: &now},
+		2: {ID: 2, UserID: 11, Host: "pending.example.org"},
+	}}
+	s := newDomainService(t, repo, stubResolver{})
+
+	tests := []struct {
+		host string
+		want uint
+	}{
+		{"go.example.org", 1},
+		{"GO.Example.org.", 1},
+		{"go.example.org:8080", 1},
+		{"pending.example.org", 0},
+		{"unknown.example.org", 0},
+		{"via.example.com", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		domain, err := s.ResolveDomain(context.Background(), tt.host)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.host, err)
+		}
+		var got uint
+		if domain != nil {
+			got = domain.ID
+		}
+		if got != tt.want {
+			t.Errorf("%q resolved to domain %d, want %d", tt.host, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/metrics"
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/linkcheck"
+)
+
+const (
+	// healthBatchSize bounds the links and the bookmarks checked per run;
+	// targets not reached are picked up by the next run.
+	healthBatchSize = 100
+	healthWorkers   = 8
+	healthErrorSize = 255
+)
+
+// CheckHealth checks the destinations of active links and the URLs of
+// bookmarks not checked within the configured interval, then publishes the
+// number of broken targets.
+func (s *service) CheckHealth(ctx context.Context) error {
+	const op = "service.CheckHealth"
+	log := s.log.With("op", op)
+
+	before := time.Now().Add(-s.cfg.HealthInterval)
+	links, err := s.repo.ListDueHealthLinks(ctx, before, healthBatchSize)
+	if err != nil {
+		return err
+	}
+	bookmarks, err := s.repo.ListDueHealthBookmarks(ctx, before, healthBatchSize)
+	if err != nil {
+		return err
+	}
+
+	jobs := make([]func(), 0, len(links)+len(bookmarks))
+	for _, link := range links {
+		jobs = append(jobs, func() {
+			health, ok := s.checkTarget(ctx, link.Destination, link.Health)
+			if !ok {
+				return
+			}
+			if err := s.repo.UpdateLinkHealth(ctx, link.ID, health); err != nil {
+				log.Error("failed to store link health", "link", link.ID, "error", err)
+			}
+		})
+	}
+	for _, bookmark := range bookmarks {
+		jobs = append(jobs, func() {
+			health, ok := s.checkTarget(ctx, bookmark.URL, bookmark.Health)
+			if !ok {
+				return
+			}
+			if err := s.repo.UpdateBookmarkHealth(ctx, bookmark.ID, health); err != nil {
+				log.Error("failed to store bookmark health", "bookmark", bookmark.ID, "error", err)
+			}
+		})
+	}
+	runConcurrently(healthWorkers, jobs)
+	if len(jobs) > 0 {
+		log.Debug("checked destinations", "links", len(links), "bookmarks", len(bookmarks))
+	}
+
+	brokenLinks, err := s.repo.CountBrokenLinks(ctx)
+	if err != nil {
+		return err
+	}
+	brokenBookmarks, err := s.repo.CountBrokenBookmarks(ctx)
+	if err != nil {
+		return err
+	}
+	metrics.SetBrokenTargets("link", brokenLinks)
+	metrics.SetBrokenTargets("bookmark", brokenBookmarks)
+	return nil
+}
+
+// checkTarget checks rawURL and returns the health that follows prev. It
+// returns false when the run is cancelled, so the target stays due.
+func (s *service) checkTarget(ctx context.Context, rawURL string, prev model.Health) (model.Health, bool) {
+	res := s.checker.Check(ctx, rawURL)
+	if ctx.Err() != nil {
+		return prev, false
+	}
+	return nextHealth(prev, res, time.Now(), s.cfg.HealthMaxFailures), true
+}
+
+func nextHealth(prev model.Health, res linkcheck.Result, now time.Time, maxFailures int) model.Health {
+	chain, _ := json.Marshal(append([]string{}, res.Chain...))
+	health := model.Health{
+		CheckedAt:     &now,
+		LastSuccessAt: prev.LastSuccessAt,
+		RedirectChain: model.JSONText(chain),
+		Error:         truncate(res.Error, healthErrorSize),
+		StatusCode:    res.StatusCode,
+		LatencyMS:     res.Latency.Milliseconds(),
+	}
+	if res.OK {
+		health.LastSuccessAt = &now
+		return health
+	}
+	health.Failures = prev.Failures + 1
+	health.Broken = health.Failures >= maxFailures
+	return health
+}
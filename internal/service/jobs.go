@@ -0,0 +1,24 @@
+package service
+
+import "sync"
+
+// runConcurrently runs jobs on at most workers goroutines and waits for all
+// of them to finish.
+func runConcurrently(workers int, jobs []func()) {
+	queue := make(chan func())
+	var wg sync.WaitGroup
+	for i := 0; i < min(workers, len(jobs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				job()
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+}
@@ -0,0 +1,722 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"github.com/OxytocinGroup/theca-v3/internal/repository"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	jwtauth "github.com/OxytocinGroup/theca-v3/internal/utils/jwt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+func (s *service) CreateLink(ctx context.Context, userID uint, req model.CreateLinkRequest) (*model.Link, error) {
+	const op = "service.CreateLink"
+	log := s.log.With("op", op)
+
+	link, err := s.prepareLink(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.insertLink(ctx, s.repo, link, req.Tags, false); err != nil {
+		return nil, err
+	}
+
+	log.Debug("link created", "link", link.ID, "user", userID)
+	return link, nil
+}
+
+// BulkCreateLinks creates one link per row. In atomic mode either every row
+// is created or none is; otherwise each row succeeds or fails on its own.
+// The per-row results are returned in both cases.
+func (s *service) BulkCreateLinks(ctx context.Context, userID uint, rows []model.BulkLinkRow, atomic bool) (*model.BulkLinksResponse, error) {
+	const op = "service.BulkCreateLinks"
+	log := s.log.With("op", op)
+
+	resp := &model.BulkLinksResponse{Results: make([]model.BulkLinkResult, len(rows))}
+	links := make([]*model.Link, len(rows))
+	for i, row := range rows {
+		resp.Results[i].Row = i + 1
+		err := row.Err
+		var link *model.Link
+		if err == nil {
+			link, err = s.prepareLink(ctx, userID, row.Request)
+		}
+		if err != nil {
+			resp.Results[i].Error = customerrors.ToAPIError(err)
+			resp.Failed++
+			continue
+		}
+		links[i] = link
+	}
+
+	if !atomic {
+		if err := s.claimBulkCodes(ctx, s.repo, links, resp); err != nil {
+			log.Error("failed to claim bulk link codes", "error", err)
+			return nil, err
+		}
+		for i, link := range links {
+			if link == nil || resp.Results[i].Error != nil {
+				continue
+			}
+			if err := s.insertLink(ctx, s.repo, link, rows[i].Request.Tags, true); err != nil {
+				resp.Results[i].Error = customerrors.ToAPIError(err)
+				resp.Failed++
+				continue
+			}
+			resp.Results[i].ID, resp.Results[i].Code = link.ID, link.Code
+			resp.Created++
+		}
+		resp.Committed = resp.Created > 0
+		log.Debug("bulk links created", "user", userID, "created", resp.Created, "failed", resp.Failed)
+		return resp, nil
+	}
+
+	if resp.Failed > 0 {
+		return resp, s.rollBackBulk(resp, -1)
+	}
+
+	failedRow := -1
+	err := s.repo.Transaction(ctx, func(repo repository.Repository) error {
+		if err := s.claimBulkCodes(ctx, repo, links, resp); err != nil {
+			return err
+		}
+		if resp.Failed > 0 {
+			// Nothing has been written; the rows are rolled back below.
+			return nil
+		}
+		for i, link := range links {
+			if err := s.insertLink(ctx, repo, link, rows[i].Request.Tags, true); err != nil {
+				failedRow = i
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if failedRow < 0 {
+			log.Error("failed to create bulk links", "error", err)
+			return nil, err
+		}
+		resp.Results[failedRow].Error = customerrors.ToAPIError(err)
+		resp.Failed++
+	}
+	if resp.Failed > 0 {
+		return resp, s.rollBackBulk(resp, failedRow)
+	}
+
+	for i, link := range links {
+		resp.Results[i].ID, resp.Results[i].Code = link.ID, link.Code
+	}
+	resp.Created = len(links)
+	resp.Committed = true
+
+	log.Debug("bulk links created", "user", userID, "created", resp.Created)
+	return resp, nil
+}
+
+// claimBulkCodes checks the aliases of a batch and generates codes for its
+// other links with one query per domain and attempt, instead of one per link.
+// Links that cannot have their code get a row error in resp. Codes derived
+// from the primary key are left to createLinkRow.
+func (s *service) claimBulkCodes(ctx context.Context, repo repository.Repository, links []*model.Link, resp *model.BulkLinksResponse) error {
+	fail := func(i int, err error) {
+		resp.Results[i].Error = customerrors.ToAPIError(err)
+		resp.Failed++
+	}
+
+	// claimed holds the codes of the batch per domain, so two rows cannot
+	// end up with the same one.
+	claimed := make(map[uint]map[string]struct{})
+	candidates := make(map[int]string)
+	var pending []int
+	for i, link := range links {
+		if link == nil {
+			continue
+		}
+		if claimed[link.DomainID] == nil {
+			claimed[link.DomainID] = make(map[string]struct{})
+		}
+		switch {
+		case link.Code != "":
+			candidates[i] = link.Code
+		case !s.codes.NeedsID():
+			pending = append(pending, i)
+		}
+	}
+
+	for attempt := 0; attempt < s.cfg.CodeMaxAttempts && (len(candidates) > 0 || len(pending) > 0); attempt++ {
+		for _, i := range pending {
+			code, err := s.codes.Generate(0, attempt)
+			if err != nil {
+				return err
+			}
+			if !s.isReservedCode(code) {
+				candidates[i] = code
+			}
+		}
+
+		byDomain := make(map[uint][]string)
+		for i, code := range candidates {
+			byDomain[links[i].DomainID] = append(byDomain[links[i].DomainID], code)
+		}
+		taken := make(map[uint]map[string]struct{}, len(byDomain))
+		for domainID, codes := range byDomain {
+			held, err := repo.TakenLinkCodes(ctx, domainID, codes)
+			if err != nil {
+				return err
+			}
+			taken[domainID] = make(map[string]struct{}, len(held))
+			for _, code := range held {
+				taken[domainID][code] = struct{}{}
+			}
+		}
+
+		// Rows are settled in order, so the first of two rows asking for
+		// the same alias gets it.
+		var retry []int
+		for _, i := range slices.Sorted(maps.Keys(candidates)) {
+			link, code := links[i], candidates[i]
+			_, inUse := taken[link.DomainID][code]
+			if _, ok := claimed[link.DomainID][code]; ok {
+				inUse = true
+			}
+			switch {
+			case !inUse:
+				claimed[link.DomainID][code] = struct{}{}
+				link.Code = code
+			case link.Code != "":
+				fail(i, customerrors.New(customerrors.CodeDataConflict, "Этот алиас уже занят"))
+			default:
+				retry = append(retry, i)
+			}
+		}
+		for _, i := range pending {
+			if _, ok := candidates[i]; !ok {
+				retry = append(retry, i)
+			}
+		}
+		pending, candidates = retry, make(map[int]string)
+	}
+
+	for _, i := range pending {
+		fail(i, customerrors.New(customerrors.CodeInternalError, "Не удалось сгенерировать короткий код"))
+	}
+	return nil
+}
+
+// rollBackBulk marks every row without its own error as rolled back and
+// returns the error describing the aborted batch.
+func (s *service) rollBackBulk(resp *model.BulkLinksResponse, failedRow int) error {
+	rolledBack := customerrors.ToAPIError(customerrors.New(customerrors.CodeDataInvalid, "Строка не создана: пакет отменён из-за ошибок"))
+	for i := range resp.Results {
+		if i == failedRow || resp.Results[i].Error != nil {
+			continue
+		}
+		resp.Results[i].Error = rolledBack
+	}
+	resp.Created = 0
+	return customerrors.New(customerrors.CodeDataInvalid, "Пакет не создан: исправьте ошибки в строках")
+}
+
+// prepareLink validates a create request and builds the link without
+// writing anything to the database.
+func (s *service) prepareLink(ctx context.Context, userID uint, req model.CreateLinkRequest) (*model.Link, error) {
+	const op = "service.prepareLink"
+	log := s.log.With("op", op)
+
+	if err := validateDestination(req.Destination); err != nil {
+		return nil, err
+	}
+	if req.FallbackURL != "" {
+		if err := validateDestination(req.FallbackURL); err != nil {
+			return nil, err
+		}
+	}
+	if req.Alias != "" {
+		if err := s.validateAliasFormat(req.Alias); err != nil {
+			return nil, err
+		}
+	}
+
+	domainID, err := s.userDomainID(ctx, userID, req.DomainID)
+	if err != nil {
+		return nil, err
+	}
+	campaignID, err := s.userCampaignID(ctx, userID, req.CampaignID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := buildLinkRules(req.Rules)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := buildLinkVariants(req.Variants)
+	if err != nil {
+		return nil, err
+	}
+	deepLink, err := validateDeepLink(req.DeepLink)
+	if err != nil {
+		return nil, err
+	}
+
+	link := &model.Link{
+		DomainID:       domainID,
+		CampaignID:     campaignID,
+		UTM:            normalizeUTM(req.UTM),
+		UTMOverride:    req.UTMOverride,
+		DeepLink:       deepLink,
+		Rules:          rules,
+		Variants:       variants,
+		StickyVariants: req.StickyVariants,
+		Code:           req.Alias,
+		Destination:    req.Destination,
+		Title:          req.Title,
+		UserID:         userID,
+		ExpiresAt:      utcTime(req.ExpiresAt),
+		MaxClicks:      req.MaxClicks,
+		FallbackURL:    req.FallbackURL,
+		RedirectType:   http.StatusFound,
+		Enabled:        true,
+	}
+	if req.RedirectType != 0 {
+		link.RedirectType = req.RedirectType
+	}
+	// Links without a title get one from the destination page.
+	link.MetadataPending = req.Title == ""
+	if req.Enabled != nil {
+		link.Enabled = *req.Enabled
+	}
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			log.Error("failed to hash link password", "error", err)
+			return nil, err
+		}
+		link.PasswordHash = string(hash)
+	}
+
+	return link, nil
+}
+
+// insertLink stores a prepared link together with its tags and its first
+// revision in one transaction. claimed tells that the code of the link, if
+// any, has been checked already.
+func (s *service) insertLink(ctx context.Context, repo repository.Repository, link *model.Link, tagNames []string, claimed bool) error {
+	return repo.Transaction(ctx, func(repo repository.Repository) error {
+		tags, err := repo.FindOrCreateTags(ctx, link.UserID, normalizeTags(tagNames))
+		if err != nil {
+			return err
+		}
+		link.Tags = tags
+
+		if err := s.createLinkRow(ctx, repo, link, claimed); err != nil {
+			return err
+		}
+
+		rev, err := newRevision(link, link.UserID, model.RevisionActionCreate, nil)
+		if err != nil {
+			return err
+		}
+		return repo.CreateLinkRevision(ctx, rev)
+	})
+}
+
+// createLinkRow assigns a code to the link, unless it carries an alias or a
+// claimed code, and inserts it.
+func (s *service) createLinkRow(ctx context.Context, repo repository.Repository, link *model.Link, claimed bool) error {
+	const op = "service.createLinkRow"
+	log := s.log.With("op", op)
+
+	switch {
+	case claimed && link.Code != "":
+		return repo.CreateLink(ctx, link)
+	case link.Code != "":
+		if err := s.checkAliasFree(ctx, repo, link.DomainID, link.Code); err != nil {
+			return err
+		}
+		return repo.CreateLink(ctx, link)
+	case s.codes.NeedsID():
+		// The real code is derived from the primary key, so the row is
+		// inserted with a placeholder that can never collide with a valid
+		// alias or generated code.
+		placeholder, err := placeholderCode()
+		if err != nil {
+			return err
+		}
+		link.Code = placeholder
+		if err := repo.CreateLink(ctx, link); err != nil {
+			return err
+		}
+
+		if link.Code, err = s.generateCode(ctx, repo, link); err != nil {
+			return err
+		}
+		return repo.SaveLink(ctx, link)
+	default:
+		code, err := s.generateCode(ctx, repo, link)
+		if err != nil {
+			log.Error("failed to generate code", "error", err)
+			return err
+		}
+		link.Code = code
+		return repo.CreateLink(ctx, link)
+	}
+}
+
+func (s *service) GetLink(ctx context.Context, userID, id uint) (*model.Link, error) {
+	return s.repo.GetUserLink(ctx, userID, id)
+}
+
+// ListLinks returns a page of the user's links and the cursor of the next
+// page, empty on the last one.
+func (s *service) ListLinks(ctx context.Context, userID uint, filter model.LinkFilter, page model.PageRequest) ([]model.Link, string, error) {
+	filter.Tags = normalizeTags(filter.Tags)
+	page.Q = strings.TrimSpace(page.Q)
+	return s.repo.ListUserLinks(ctx, userID, filter, page)
+}
+
+func (s *service) UpdateLink(ctx context.Context, userID, id uint, req model.UpdateLinkRequest) (*model.Link, error) {
+	const op = "service.UpdateLink"
+	log := s.log.With("op", op)
+
+	link, err := s.repo.GetUserLink(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshotOf(link)
+
+	if req.Destination != nil {
+		if err := validateDestination(*req.Destination); err != nil {
+			return nil, err
+		}
+		if link.Destination != *req.Destination {
+			// The old destination's health says nothing about the new one.
+			link.Health = model.Health{}
+		}
+		link.Destination = *req.Destination
+	}
+	if req.Title != nil {
+		link.Title = *req.Title
+	}
+	if req.RedirectType != nil {
+		link.RedirectType = *req.RedirectType
+	}
+	if req.Enabled != nil {
+		link.Enabled = *req.Enabled
+	}
+	if req.CampaignID != nil {
+		if link.CampaignID, err = s.userCampaignID(ctx, userID, *req.CampaignID); err != nil {
+			return nil, err
+		}
+	}
+	if req.UTM != nil {
+		link.UTM = normalizeUTM(*req.UTM)
+	}
+	if req.UTMOverride != nil {
+		link.UTMOverride = *req.UTMOverride
+	}
+	if req.Rules != nil {
+		if link.Rules, err = buildLinkRules(*req.Rules); err != nil {
+			return nil, err
+		}
+	}
+	if req.Variants != nil {
+		if link.Variants, err = buildLinkVariants(*req.Variants); err != nil {
+			return nil, err
+		}
+	}
+	if req.StickyVariants != nil {
+		link.StickyVariants = *req.StickyVariants
+	}
+	if req.DeepLink != nil {
+		if link.DeepLink, err = validateDeepLink(*req.DeepLink); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.FallbackURL != nil {
+		if *req.FallbackURL != "" {
+			if err := validateDestination(*req.FallbackURL); err != nil {
+				return nil, err
+			}
+		}
+		link.FallbackURL = *req.FallbackURL
+	}
+
+	if req.Password != nil {
+		link.PasswordHash = ""
+		if *req.Password != "" {
+			if len(*req.Password) < 4 {
+				return nil, customerrors.New(customerrors.CodeDataInvalid, "Пароль ссылки должен содержать минимум 4 символа")
+			}
+			hash, err := bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost)
+			if err != nil {
+				log.Error("failed to hash link password", "error", err)
+				return nil, err
+			}
+			link.PasswordHash = string(hash)
+		}
+	}
+
+	limitsChanged := req.ExpiresAt != nil || req.ClearExpiration || req.MaxClicks != nil
+	if req.ClearExpiration {
+		link.ExpiresAt = nil
+	} else if req.ExpiresAt != nil {
+		link.ExpiresAt = utcTime(req.ExpiresAt)
+	}
+	if req.MaxClicks != nil {
+		link.MaxClicks = *req.MaxClicks
+	}
+	if limitsChanged {
+		// Let the reaper re-evaluate the link against its new limits.
+		link.ExpiredAt = nil
+	}
+
+	// New tags are created in the transaction that saves the link, so a
+	// failed save does not leave them behind.
+	err = s.repo.Transaction(ctx, func(repo repository.Repository) error {
+		if req.Tags != nil {
+			tags, err := repo.FindOrCreateTags(ctx, userID, normalizeTags(*req.Tags))
+			if err != nil {
+				return err
+			}
+			link.Tags = tags
+		}
+
+		rev, err := newRevision(link, userID, model.RevisionActionUpdate, &before)
+		if err != nil {
+			return err
+		}
+		if rev == nil {
+			return repo.SaveLink(ctx, link)
+		}
+		return repo.SaveLinkWithRevision(ctx, link, rev)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("link updated", "link", link.ID, "user", userID)
+	return link, nil
+}
+
+// DeleteLink moves a link to the trash; it stops resolving right away.
+func (s *service) DeleteLink(ctx context.Context, userID, id uint) error {
+	const op = "service.DeleteLink"
+	log := s.log.With("op", op)
+
+	link, err := s.repo.GetUserLink(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteLink(ctx, link); err != nil {
+		return err
+	}
+
+	log.Debug("link moved to trash", "link", link.ID, "user", userID)
+	return nil
+}
+
+// ResolveLink returns the link a public short code points to. Disabled links
+// are reported as missing so they are indistinguishable from unknown codes.
+func (s *service) ResolveLink(ctx context.Context, domain *model.Domain, code string) (*model.Link, error) {
+	var domainID uint
+	if domain != nil {
+		domainID = domain.ID
+	}
+
+	link, err := s.repo.GetLinkByCode(ctx, domainID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !link.Enabled {
+		return nil, customerrors.New(customerrors.CodeDataNotFound, "Ссылка не найдена")
+	}
+	return link, nil
+}
+
+// CheckLinkAvailable reports CodeLinkGone once a link has passed its
+// expiration date or used up its click budget.
+func (s *service) CheckLinkAvailable(ctx context.Context, link *model.Link) error {
+	gone := customerrors.New(customerrors.CodeLinkGone, "Срок действия ссылки истёк")
+
+	if link.ExpiredAt != nil {
+		return gone
+	}
+	if link.ExpiresAt != nil && !time.Now().Before(*link.ExpiresAt) {
+		return gone
+	}
+	// The count trails the clicks still buffered, so a busy link may
+	// overshoot its limit by a few.
+	if link.MaxClicks > 0 && link.ClickCount >= int64(link.MaxClicks) {
+		return gone
+	}
+
+	return nil
+}
+
+// utcTime returns t in UTC. Times are stored in UTC, since SQLite compares
+// them as text and would misorder times with different offsets.
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+func (s *service) ReapExpiredLinks(ctx context.Context) error {
+	const op = "service.ReapExpiredLinks"
+	log := s.log.With("op", op)
+
+	marked, err := s.repo.MarkExpiredLinks(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if marked > 0 {
+		log.Info("marked expired links", "count", marked)
+	}
+	return nil
+}
+
+// UnlockLink checks the password of a protected link and returns a signed
+// token that proves the visitor entered it.
+func (s *service) UnlockLink(ctx context.Context, link *model.Link, password string) (string, error) {
+	const op = "service.UnlockLink"
+	log := s.log.With("op", op)
+
+	if err := bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)); err != nil {
+		return "", customerrors.New(customerrors.CodeInvalidPassword, "Неверный пароль")
+	}
+
+	token, err := jwtauth.GenerateLinkUnlockToken(link.ID, passwordTag(link.PasswordHash), s.cfg.LinkUnlockTTL, s.cfg.LinkUnlockSecret)
+	if err != nil {
+		log.Error("failed to generate unlock token", "error", err)
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (s *service) VerifyLinkUnlock(link *model.Link, token string) bool {
+	if token == "" {
+		return false
+	}
+	claims, err := jwtauth.ParseLinkUnlockToken(token, s.cfg.LinkUnlockSecret)
+	if err != nil {
+		return false
+	}
+	return claims.LinkID == link.ID && claims.PasswordTag == passwordTag(link.PasswordHash)
+}
+
+func passwordTag(hash string) string {
+	sum := sha256.Sum256([]byte(hash))
+	return hex.EncodeToString(sum[:8])
+}
+
+// generateCode asks the configured generator for candidates until one is
+// neither reserved nor taken on the link domain, giving up after
+// CodeMaxAttempts tries.
+func (s *service) generateCode(ctx context.Context, repo repository.Repository, link *model.Link) (string, error) {
+	for attempt := range s.cfg.CodeMaxAttempts {
+		code, err := s.codes.Generate(link.ID, attempt)
+		if err != nil {
+			return "", err
+		}
+
+		if s.isReservedCode(code) {
+			continue
+		}
+
+		exists, err := repo.LinkCodeExists(ctx, link.DomainID, code)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return code, nil
+		}
+	}
+
+	return "", customerrors.New(customerrors.CodeInternalError, "Не удалось сгенерировать короткий код")
+}
+
+func (s *service) validateAliasFormat(alias string) error {
+	if len(alias) < s.cfg.AliasMinLength || len(alias) > s.cfg.AliasMaxLength {
+		return customerrors.New(customerrors.CodeDataInvalid,
+			fmt.Sprintf("Длина алиаса должна быть от %d до %d символов", s.cfg.AliasMinLength, s.cfg.AliasMaxLength))
+	}
+	if !aliasPattern.MatchString(alias) {
+		return customerrors.New(customerrors.CodeDataInvalid, "Алиас может содержать только латинские буквы, цифры, '-' и '_'")
+	}
+	if s.isReservedCode(alias) {
+		return customerrors.New(customerrors.CodeDataConflict, "Этот алиас зарезервирован")
+	}
+	return nil
+}
+
+func (s *service) checkAliasFree(ctx context.Context, repo repository.Repository, domainID uint, alias string) error {
+	exists, err := repo.LinkCodeExists(ctx, domainID, alias)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return customerrors.New(customerrors.CodeDataConflict, "Этот алиас уже занят")
+	}
+	return nil
+}
+
+func (s *service) isReservedCode(code string) bool {
+	_, ok := s.reserved[strings.ToLower(code)]
+	return ok
+}
+
+func placeholderCode() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "~" + hex.EncodeToString(b), nil
+}
+
+// normalizeTags trims tag names and drops empty and duplicate ones.
+func normalizeTags(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		tags = append(tags, name)
+	}
+	return tags
+}
+
+// validateDestination only accepts absolute http(s) URLs so a short link
+// can never redirect to javascript: or data: payloads.
+func validateDestination(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return customerrors.New(customerrors.CodeDataInvalid, "Ссылка должна быть абсолютным http(s) адресом")
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"github.com/OxytocinGroup/theca-v3/internal/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newSQLiteRepository(t *testing.T) (repository.Repository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{SkipDefaultTransaction: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	err = db.AutoMigrate(&model.User{}, &model.Link{}, &model.Domain{}, &model.Tag{}, &model.LinkRevision{}, &model.Campaign{}, &model.LinkRule{}, &model.LinkVariant{}, &model.VisitorSalt{}, &model.Click{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repository.NewRepository(db, discardLogger()), db
+}
+
+func TestUpdateLinkFailedSaveCreatesNoTags(t *testing.T) {
+	repo, db := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	link := model.Link{Code: "abc", Destination: "https://example.com/", UserID: 1, Enabled: true}
+	if err := db.Create(&link).Error; err != nil {
+		t.Fatal(err)
+	}
+	err := db.Exec(`CREATE TRIGGER fail_revisions BEFORE INSERT ON link_revisions
+		BEGIN SELECT RAISE(ABORT, 'revision rejected'); END`).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewService(repo, discardLogger(), testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	title, tags := "Renamed", []string{"fresh"}
+	if _, err := s.UpdateLink(ctx, 1, link.ID, model.UpdateLinkRequest{Title: &title, Tags: &tags}); err == nil {
+		t.Fatal("expected the save to fail")
+	}
+
+	var count int64
+	if err := db.Model(&model.Tag{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("a failed update left %d tags behind", count)
+	}
+
+	if err := db.Exec("DROP TRIGGER fail_revisions").Error; err != nil {
+		t.Fatal(err)
+	}
+	updated, err := s.UpdateLink(ctx, 1, link.ID, model.UpdateLinkRequest{Title: &title, Tags: &tags})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.Tags) != 1 || updated.Tags[0].Name != "fresh" {
+		t.Fatalf("got tags %+v, want fresh", updated.Tags)
+	}
+}
+
+func TestUpdateLinkExpiresAtOffset(t *testing.T) {
+	repo, db := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	link := model.Link{Code: "abc", Destination: "https://example.com/", UserID: 1, Enabled: true}
+	if err := db.Create(&link).Error; err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewService(repo, discardLogger(), testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An hour from now, as a client five hours behind UTC sends it.
+	expiresAt := time.Now().Add(time.Hour).In(time.FixedZone("UTC-5", -5*60*60))
+	if _, err := s.UpdateLink(ctx, 1, link.ID, model.UpdateLinkRequest{ExpiresAt: &expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ReapExpiredLinks(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var stored model.Link
+	if err := db.First(&stored, link.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.ExpiredAt != nil {
+		t.Fatal("the link was reaped an hour before it expires")
+	}
+	if !stored.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("stored expiry %v, want %v", stored.ExpiresAt, expiresAt)
+	}
+}
+
+// Bulk creation checks the codes of all rows at once, in one query per round
+// of generated codes, and settles conflicts between rows in row order.
+func TestBulkCreateLinksClaimsCodes(t *testing.T) {
+	repo, db := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	if err := db.Create(&model.Link{Code: "abc", Destination: "https://example.com/", UserID: 1, Enabled: true}).Error; err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig()
+	cfg.AliasMinLength, cfg.AliasMaxLength = 3, 64
+	svc, err := NewService(repo, discardLogger(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := svc.(*service)
+	// Every generated row gets the same candidate per attempt, the first
+	// one of which is taken.
+	s.codes = fixedCodes{"abc", "g1", "g2"}
+
+	var checks, single int
+	err = db.Callback().Query().After("gorm:query").Register("count_code_checks", func(tx *gorm.DB) {
+		sql := tx.Statement.SQL.String()
+		if strings.Contains(sql, "code IN") {
+			checks++
+		}
+		if strings.Contains(sql, "code = ") {
+			single++
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row := func(alias string) model.BulkLinkRow {
+		return model.BulkLinkRow{Request: model.CreateLinkRequest{Destination: "https://example.com/", Title: "t", Alias: alias}}
+	}
+	rows := []model.BulkLinkRow{row("fresh"), row("abc"), row("fresh"), row(""), row("")}
+
+	resp, err := s.BulkCreateLinks(ctx, 1, rows, true)
+	if err == nil || resp.Committed || resp.Failed != 2 {
+		t.Fatalf("atomic batch with conflicts: got %+v, %v", resp, err)
+	}
+	var count int64
+	if err := db.Model(&model.Link{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("the rejected batch left %d links, want 1", count)
+	}
+
+	checks = 0
+	resp, err = s.BulkCreateLinks(ctx, 1, rows, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var codes []string
+	for _, result := range resp.Results {
+		codes = append(codes, result.Code)
+	}
+	if want := []string{"fresh", "", "", "g1", "g2"}; !slices.Equal(codes, want) {
+		t.Fatalf("got codes %q, want %q", codes, want)
+	}
+	if resp.Results[1].Error == nil || resp.Results[2].Error == nil {
+		t.Fatalf("taken and repeated aliases were accepted: %+v", resp.Results)
+	}
+	if checks != 3 || single != 0 {
+		t.Fatalf("checked codes in %d batch and %d single queries, want one batch query per attempt, 3", checks, single)
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/metadata"
+)
+
+const (
+	// metadataBatchSize bounds the links and the bookmarks handled per run.
+	metadataBatchSize = 50
+	metadataWorkers   = 8
+)
+
+// Column sizes of the fields metadata is written to.
+const (
+	linkTitleSize       = 255
+	linkDescriptionSize = 1024
+	linkIconURLSize     = 2048
+	bookmarkTitleSize   = 128
+	bookmarkIconURLSize = 255
+)
+
+// FetchMetadata fills in the title, description and favicon of links created
+// without a title, and the empty title and icon of bookmarks, from their
+// pages. A failed fetch is not retried; the fields simply stay empty.
+func (s *service) FetchMetadata(ctx context.Context) error {
+	const op = "service.FetchMetadata"
+	log := s.log.With("op", op)
+
+	links, err := s.repo.ListPendingMetadataLinks(ctx, metadataBatchSize)
+	if err != nil {
+		return err
+	}
+	bookmarks, err := s.repo.ListPendingMetadataBookmarks(ctx, metadataBatchSize)
+	if err != nil {
+		return err
+	}
+	if len(links) == 0 && len(bookmarks) == 0 {
+		return nil
+	}
+
+	jobs := make([]func(), 0, len(links)+len(bookmarks))
+	for _, link := range links {
+		jobs = append(jobs, func() { s.fillLinkMetadata(ctx, link) })
+	}
+	for _, bookmark := range bookmarks {
+		jobs = append(jobs, func() { s.fillBookmarkMetadata(ctx, bookmark) })
+	}
+	runConcurrently(metadataWorkers, jobs)
+
+	log.Debug("fetched metadata", "links", len(links), "bookmarks", len(bookmarks))
+	return nil
+}
+
+func (s *service) fillLinkMetadata(ctx context.Context, link model.Link) {
+	meta, ok := s.fetchMetadata(ctx, link.Destination)
+	if !ok {
+		return
+	}
+
+	iconURL := meta.IconURL
+	if len(iconURL) > linkIconURLSize {
+		iconURL = ""
+	}
+	err := s.repo.UpdateLinkMetadata(ctx, link.ID, truncate(meta.Title, linkTitleSize), truncate(meta.Description, linkDescriptionSize), iconURL)
+	if err != nil {
+		s.log.Error("failed to store link metadata", "op", "service.fillLinkMetadata", "link", link.ID, "error", err)
+	}
+}
+
+func (s *service) fillBookmarkMetadata(ctx context.Context, bookmark model.Bookmark) {
+	meta, ok := s.fetchMetadata(ctx, bookmark.URL)
+	if !ok {
+		return
+	}
+
+	iconURL := meta.IconURL
+	if len(iconURL) > bookmarkIconURLSize {
+		iconURL = ""
+	}
+	if err := s.repo.UpdateBookmarkMetadata(ctx, bookmark.ID, truncate(meta.Title, bookmarkTitleSize), iconURL); err != nil {
+		s.log.Error("failed to store bookmark metadata", "op", "service.fillBookmarkMetadata", "bookmark", bookmark.ID, "error", err)
+	}
+}
+
+// fetchMetadata returns empty metadata for pages that cannot be fetched, so
+// they are marked as done, and false only when the run is being cancelled and
+// the item should stay pending.
+func (s *service) fetchMetadata(ctx context.Context, rawURL string) (*metadata.Metadata, bool) {
+	meta, err := s.metadata.Fetch(ctx, rawURL)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		s.log.Debug("failed to fetch metadata", "op", "service.fetchMetadata", "url", rawURL, "error", err)
+		return &metadata.Metadata{}, true
+	}
+	return meta, true
+}
+
+// truncate shortens s to at most n bytes without splitting a character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
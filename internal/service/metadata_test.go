@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"github.com/OxytocinGroup/theca-v3/internal/repository"
+)
+
+// metadataRepo hands out pending links once and records what is stored.
+type metadataRepo struct {
+	repository.Repository
+	mu      sync.Mutex
+	pending []model.Link
+	titles  map[uint]string
+	icons   map[uint]string
+}
+
+func (r *metadataRepo) ListPendingMetadataLinks(context.Context, int) ([]model.Link, error) {
+	links := r.pending
+	r.pending = nil
+	return links, nil
+}
+
+func (r *metadataRepo) ListPendingMetadataBookmarks(context.Context, int) ([]model.Bookmark, error) {
+	return nil, nil
+}
+
+func (r *metadataRepo) UpdateLinkMetadata(_ context.Context, id uint, title, _, iconURL string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.titles[id] = title
+	r.icons[id] = iconURL
+	return nil
+}
+
+func TestFetchMetadataPrivateAddress(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<head><title>Internal dashboard</title></head>`))
+	}))
+	defer srv.Close()
+
+	cfg := testConfig()
+	cfg.FetchTimeout = time.Second
+	cfg.FetchHostLimit = 2
+	cfg.MetadataMaxBytes = 1024
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantTitle string
+	}{
+		// The default client only connects to public addresses, so the
+		// loopback server is never reached and the link is marked done.
+		{"default client", nil, ""},
+		{"test client", []Option{WithHTTPClient(srv.Client())}, "Internal dashboard"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits = 0
+			repo := &metadataRepo{
+				pending: []model.Link{{ID: 1, Destination: srv.URL + "/"}},
+				titles:  map[uint]string{},
+				icons:   map[uint]string{},
+			}
+			s, err := NewService(repo, discardLogger(), cfg, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.FetchMetadata(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			title, ok := repo.titles[1]
+			if !ok {
+				t.Fatal("link metadata was not stored")
+			}
+			if title != tt.wantTitle {
+				t.Fatalf("stored title %q, want %q", title, tt.wantTitle)
+			}
+			if tt.wantTitle == "" && hits != 0 {
+				t.Fatal("the default client reached a loopback address")
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/qrcode"
+)
+
+const (
+	defaultQRSize = 512
+	// maxLogoBytes and maxLogoSide bound the logo a QR code request may
+	// make the server download and decode.
+	maxLogoBytes = 1 << 20
+	maxLogoSide  = 2048
+)
+
+// LinkQRCode renders the QR code of a link. The encoded short URL carries
+// src=qr so scans show up as such in click analytics. A logo raises the
+// error correction level to H unless Q or H was requested.
+func (s *service) LinkQRCode(ctx context.Context, userID, linkID uint, req model.QRCodeRequest) (*model.QRCode, error) {
+	const op = "service.LinkQRCode"
+	log := s.log.With("op", op)
+
+	link, err := s.repo.GetUserLink(ctx, userID, linkID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Logo != "" && req.Format == "svg" {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Логотип поддерживается только для PNG")
+	}
+
+	level, _ := qrcode.ParseLevel(req.Level)
+	style := qrcode.Style{Size: defaultQRSize, Margin: qrcode.DefaultMargin}
+	if req.Size != 0 {
+		style.Size = req.Size
+	}
+	if req.Margin != nil {
+		style.Margin = *req.Margin
+	}
+	if style.Foreground, err = parseHexColor(req.Foreground, color.Black); err != nil {
+		return nil, err
+	}
+	if style.Background, err = parseHexColor(req.Background, color.White); err != nil {
+		return nil, err
+	}
+	if req.Logo != "" {
+		if style.Logo, err = s.fetchLogo(ctx, req.Logo); err != nil {
+			return nil, err
+		}
+		if level < qrcode.LevelQ {
+			level = qrcode.LevelH
+		}
+	}
+
+	target, err := s.shortURL(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	code, err := qrcode.Encode([]byte(target+"?src="+model.ClickSourceQR), level)
+	if err != nil {
+		log.Error("failed to encode qr code", "link", link.ID, "error", err)
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	qr := &model.QRCode{ContentType: "image/png"}
+	if req.Format == "svg" {
+		qr.ContentType = "image/svg+xml"
+		err = code.SVG(&buf, style)
+	} else {
+		err = code.PNG(&buf, style)
+	}
+	if err != nil {
+		log.Error("failed to render qr code", "link", link.ID, "error", err)
+		return nil, err
+	}
+	qr.Data = buf.Bytes()
+
+	return qr, nil
+}
+
+// shortURL returns the public URL of a link on its domain.
+func (s *service) shortURL(ctx context.Context, link *model.Link) (string, error) {
+	host := s.cfg.DefaultDomain
+	if link.DomainID != 0 {
+		domain, err := s.repo.GetUserDomain(ctx, link.UserID, link.DomainID)
+		if err != nil {
+			return "", err
+		}
+		host = domain.Host
+	}
+	u := url.URL{Scheme: s.cfg.ShortLinkScheme, Host: host, Path: "/" + link.Code}
+	return u.String(), nil
+}
+
+func (s *service) fetchLogo(ctx context.Context, rawURL string) (image.Image, error) {
+	const op = "service.fetchLogo"
+	log := s.log.With("op", op)
+
+	invalid := customerrors.New(customerrors.CodeDataInvalid, "Не удалось загрузить логотип")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil || (req.URL.Scheme != "http" && req.URL.Scheme != "https") {
+		return nil, invalid
+	}
+	resp, err := s.http.Do(req)
+	if err != nil {
+		log.Debug("failed to fetch logo", "url", rawURL, "error", err)
+		return nil, invalid
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Debug("unexpected logo response", "url", rawURL, "status", resp.StatusCode)
+		return nil, invalid
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxLogoBytes+1))
+	if err != nil || len(data) > maxLogoBytes {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Логотип слишком большой")
+	}
+
+	// Check the dimensions before decoding, so a small file cannot expand
+	// into a huge image in memory.
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Неподдерживаемый формат логотипа")
+	}
+	if cfg.Width > maxLogoSide || cfg.Height > maxLogoSide {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Логотип слишком большой")
+	}
+	logo, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Неподдерживаемый формат логотипа")
+	}
+	return logo, nil
+}
+
+// parseHexColor parses RRGGBB or RRGGBBAA, returning def for an empty value.
+func parseHexColor(value string, def color.Color) (color.Color, error) {
+	if value == "" {
+		return def, nil
+	}
+	b, err := hex.DecodeString(value)
+	if err != nil || (len(b) != 3 && len(b) != 4) {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, fmt.Sprintf("Неверный цвет: %s", value))
+	}
+	c := color.NRGBA{R: b[0], G: b[1], B: b[2], A: 0xff}
+	if len(b) == 4 {
+		c.A = b[3]
+	}
+	return c, nil
+}
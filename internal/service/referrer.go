@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/referrer"
+)
+
+// newReferrerClassifier extends the built-in referrer table with host=class
+// entries from the config.
+func (s *service) newReferrerClassifier() *referrer.Classifier {
+	classifier := referrer.NewClassifier()
+	for _, entry := range s.cfg.ReferrerClasses {
+		host, class, _ := strings.Cut(entry, "=")
+		if err := classifier.Add(host, strings.TrimSpace(class)); err != nil {
+			s.log.Warn("ignoring invalid referrer class", "entry", entry, "error", err)
+		}
+	}
+	return classifier
+}
+
+// classifyReferrer sets where a click came from. The utm_source of the short
+// URL wins over the Referer header, which browsers often trim or drop.
+func (s *service) classifyReferrer(click *model.Click) {
+	var src referrer.Source
+	if strings.TrimSpace(click.UTMSource) != "" {
+		src = s.referrer.Campaign(click.UTMSource, click.UTMMedium)
+	} else {
+		src = s.referrer.Referrer(click.Referrer, click.Host, s.cfg.DefaultDomain)
+	}
+	click.ReferrerHost = truncate(src.Host, 255)
+	click.ReferrerClass = src.Class
+}
+
+// LinkReferrers breaks the clicks of one link down by referrer.
+func (s *service) LinkReferrers(ctx context.Context, userID, linkID uint, req model.ReferrersRequest) (*model.ReferrerBreakdown, error) {
+	link, err := s.repo.GetUserLink(ctx, userID, linkID)
+	if err != nil {
+		return nil, err
+	}
+
+	req.LinkFilter = model.LinkFilter{}
+	req.LinkID = link.ID
+	return s.referrerBreakdown(ctx, userID, req)
+}
+
+// CampaignReferrers breaks the clicks across all links of a campaign down by
+// referrer.
+func (s *service) CampaignReferrers(ctx context.Context, userID, id uint, req model.ReferrersRequest) (*model.ReferrerBreakdown, error) {
+	campaign, err := s.repo.GetUserCampaign(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	req.CampaignID = &campaign.ID
+	return s.referrerBreakdown(ctx, userID, req)
+}
+
+func (s *service) referrerBreakdown(ctx context.Context, userID uint, req model.ReferrersRequest) (*model.ReferrerBreakdown, error) {
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Начало периода должно быть раньше его конца")
+	}
+	req.Tags = normalizeTags(req.Tags)
+	if req.Limit == 0 {
+		req.Limit = model.DefaultReferrerLimit
+	}
+
+	return s.repo.CountClicksByReferrer(ctx, userID, req.StatsFilter, req.Limit)
+}
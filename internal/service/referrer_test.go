@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/referrer"
+)
+
+func TestClassifyReferrer(t *testing.T) {
+	cfg := testConfig()
+	cfg.DefaultDomain = "via.example.com"
+	cfg.ReferrerClasses = []string{"partner.example.org=social", "invalid", "bad.example.org=paid"}
+	s, err := NewService(nil, discardLogger(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		click     model.Click
+		wantHost  string
+		wantClass string
+	}{
+		{"direct", model.Click{}, "", referrer.ClassDirect},
+		{"search", model.Click{Referrer: "https://www.google.de/"}, "google.com", referrer.ClassSearch},
+		{"default domain", model.Click{Referrer: "https://via.example.com/abc+", Host: "go.example.net"}, "via.example.com", referrer.ClassInternal},
+		{"custom domain", model.Click{Referrer: "https://go.example.net/abc+", Host: "go.example.net"}, "go.example.net", referrer.ClassInternal},
+		{"configured", model.Click{Referrer: "https://partner.example.org/"}, "partner.example.org", referrer.ClassSocial},
+		{"invalid entry ignored", model.Click{Referrer: "https://bad.example.org/"}, "bad.example.org", referrer.ClassReferral},
+		{"utm source wins", model.Click{Referrer: "https://www.google.com/", UTMSource: "newsletter", UTMMedium: "email"}, "newsletter", referrer.ClassEmail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			click := tt.click
+			s.(*service).classifyReferrer(&click)
+			if click.ReferrerHost != tt.wantHost || click.ReferrerClass != tt.wantClass {
+				t.Fatalf("got %q %s, want %q %s", click.ReferrerHost, click.ReferrerClass, tt.wantHost, tt.wantClass)
+			}
+		})
+	}
+}
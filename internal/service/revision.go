@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	"github.com/OxytocinGroup/theca-v3/internal/repository"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+)
+
+func (s *service) ListLinkRevisions(ctx context.Context, userID, linkID uint) ([]model.LinkRevision, error) {
+	link, err := s.repo.GetUserLink(ctx, userID, linkID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListLinkRevisions(ctx, link.ID)
+}
+
+// RollbackLink restores the settings a link had at the given revision. The
+// rollback is recorded as a new revision, so history is never rewritten.
+// The code, domain and password are not versioned and stay as they are.
+func (s *service) RollbackLink(ctx context.Context, userID, linkID, number uint) (*model.Link, error) {
+	const op = "service.RollbackLink"
+	log := s.log.With("op", op)
+
+	link, err := s.repo.GetUserLink(ctx, userID, linkID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.repo.GetLinkRevision(ctx, link.ID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot model.LinkSnapshot
+	if err := json.Unmarshal([]byte(target.Snapshot), &snapshot); err != nil {
+		log.Error("failed to decode revision snapshot", "revision", target.ID, "error", err)
+		return nil, customerrors.NewWithError(err, customerrors.CodeInternalError, "Не удалось прочитать ревизию")
+	}
+
+	before := snapshotOf(link)
+	if link.Destination != snapshot.Destination {
+		link.Health = model.Health{}
+	}
+	link.Destination = snapshot.Destination
+	link.Title = snapshot.Title
+	link.FallbackURL = snapshot.FallbackURL
+	link.ExpiresAt = utcTime(snapshot.ExpiresAt)
+	link.MaxClicks = snapshot.MaxClicks
+	link.RedirectType = snapshot.RedirectType
+	link.Enabled = snapshot.Enabled
+	link.UTM = snapshot.UTM
+	link.UTMOverride = snapshot.UTMOverride
+	link.DeepLink = snapshot.DeepLink
+	link.Rules = snapshot.Rules
+	link.Variants = snapshot.Variants
+	link.StickyVariants = snapshot.StickyVariants
+	link.ExpiredAt = nil
+
+	// The campaign may have been deleted since; the link then stays
+	// detached rather than pointing at nothing.
+	link.CampaignID, err = s.userCampaignID(ctx, link.UserID, snapshot.CampaignID)
+	if customerrors.IsErrorCode(err, customerrors.CodeDataNotFound) {
+		link.CampaignID, err = 0, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.Transaction(ctx, func(repo repository.Repository) error {
+		tags, err := repo.FindOrCreateTags(ctx, link.UserID, snapshot.Tags)
+		if err != nil {
+			return err
+		}
+		link.Tags = tags
+
+		rev, err := newRevision(link, userID, model.RevisionActionRollback, &before)
+		if err != nil {
+			return err
+		}
+		rev.RestoredFrom = &target.Number
+		return repo.SaveLinkWithRevision(ctx, link, rev)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("link rolled back", "link", link.ID, "revision", number, "user", userID)
+	return link, nil
+}
+
+func snapshotOf(link *model.Link) model.LinkSnapshot {
+	tags := make([]string, 0, len(link.Tags))
+	for _, tag := range link.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	return model.LinkSnapshot{
+		ExpiresAt:         link.ExpiresAt,
+		Code:              link.Code,
+		Destination:       link.Destination,
+		FallbackURL:       link.FallbackURL,
+		Title:             link.Title,
+		UTM:               link.UTM,
+		DeepLink:          link.DeepLink,
+		Tags:              tags,
+		Rules:             link.Rules,
+		Variants:          link.Variants,
+		DomainID:          link.DomainID,
+		CampaignID:        link.CampaignID,
+		MaxClicks:         link.MaxClicks,
+		RedirectType:      link.RedirectType,
+		UTMOverride:       link.UTMOverride,
+		StickyVariants:    link.StickyVariants,
+		Enabled:           link.Enabled,
+		PasswordProtected: link.PasswordHash != "",
+	}
+}
+
+// newRevision builds the revision describing the current state of link. With
+// a previous snapshot it also records the changed fields; it returns nil when
+// nothing changed.
+func newRevision(link *model.Link, userID uint, action string, prev *model.LinkSnapshot) (*model.LinkRevision, error) {
+	current, snapshot, err := canonicalJSON(snapshotOf(link))
+	if err != nil {
+		return nil, err
+	}
+
+	rev := &model.LinkRevision{
+		LinkID:   link.ID,
+		UserID:   userID,
+		Action:   action,
+		Snapshot: model.JSONText(snapshot),
+	}
+	if prev == nil {
+		return rev, nil
+	}
+
+	previous, _, err := canonicalJSON(prev)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]map[string]any)
+	for field, to := range current {
+		if from := previous[field]; !reflect.DeepEqual(from, to) {
+			changes[field] = map[string]any{"from": from, "to": to}
+		}
+	}
+	if len(changes) == 0 && action != model.RevisionActionRollback {
+		return nil, nil
+	}
+
+	diff, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+	rev.Changes = model.JSONText(diff)
+	return rev, nil
+}
+
+// canonicalJSON encodes v with sorted keys so that snapshots of equal
+// settings are byte-for-byte identical and diff cleanly.
+func canonicalJSON(v any) (map[string]any, []byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, nil, err
+	}
+	sorted, err := json.Marshal(fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fields, sorted, nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"net"
+	"strings"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/geoip"
+	"golang.org/x/text/language"
+)
+
+// GeoLocator maps an IP address to an ISO 3166-1 alpha-2 country code.
+type GeoLocator interface {
+	Country(ip net.IP) (string, error)
+}
+
+// noGeoLocator is used when no GeoIP database is configured; country
+// conditions then never match.
+type noGeoLocator struct{}
+
+func (noGeoLocator) Country(net.IP) (string, error) {
+	return "", geoip.ErrNotFound
+}
+
+// visitorTraits are the properties of a visitor that rules match against.
+type visitorTraits struct {
+	country  string
+	device   string
+	os       string
+	language language.Tag
+}
+
+func (s *service) traitsOf(visitor *model.Visitor) visitorTraits {
+	agent := s.agents.Parse(visitor.UserAgent)
+	traits := visitorTraits{
+		device:   agent.Device,
+		os:       agent.OS,
+		language: language.Und,
+	}
+
+	if visitor.IP != nil {
+		traits.country, _ = s.geo.Country(visitor.IP)
+	}
+	// Only the most preferred language counts, so a visitor asking for
+	// French first is not sent to a German page they merely accept.
+	if tags, _, err := language.ParseAcceptLanguage(visitor.AcceptLanguage); err == nil && len(tags) > 0 {
+		traits.language = tags[0]
+	}
+	return traits
+}
+
+// matchRule returns the first rule the visitor satisfies, or nil.
+func matchRule(rules []model.LinkRule, traits visitorTraits) *model.LinkRule {
+	for i := range rules {
+		rule := &rules[i]
+		if matchesValue(rule.Countries, traits.country) &&
+			matchesValue(rule.Devices, traits.device) &&
+			matchesValue(rule.OS, traits.os) &&
+			matchesLanguage(rule.Languages, traits.language) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func matchesValue(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesLanguage compares base languages, and regions only where the rule
+// names one, so "en" matches "en-GB" but "en-GB" does not match "en-US".
+func matchesLanguage(rules []string, tag language.Tag) bool {
+	if len(rules) == 0 {
+		return true
+	}
+	if tag == language.Und {
+		return false
+	}
+
+	base, _ := tag.Base()
+	region, _ := tag.Region()
+	for _, r := range rules {
+		want, err := language.Parse(r)
+		if err != nil {
+			continue
+		}
+		wantBase, _ := want.Base()
+		if wantBase != base {
+			continue
+		}
+		if wantRegion, conf := want.Region(); conf == language.Exact && wantRegion != region {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// buildLinkRules validates rule requests and normalizes their conditions.
+func buildLinkRules(reqs []model.LinkRuleRequest) ([]model.LinkRule, error) {
+	rules := make([]model.LinkRule, 0, len(reqs))
+	for i, req := range reqs {
+		if len(req.Countries)+len(req.Devices)+len(req.OS)+len(req.Languages) == 0 {
+			return nil, customerrors.New(customerrors.CodeDataInvalid, "Правило должно содержать хотя бы одно условие")
+		}
+		if err := validateDestination(req.Destination); err != nil {
+			return nil, err
+		}
+
+		rule := model.LinkRule{
+			Destination: req.Destination,
+			Position:    i,
+		}
+		for _, c := range req.Countries {
+			rule.Countries = append(rule.Countries, strings.ToUpper(c))
+		}
+		for _, d := range req.Devices {
+			rule.Devices = append(rule.Devices, strings.ToLower(d))
+		}
+		for _, o := range req.OS {
+			rule.OS = append(rule.OS, strings.ToLower(o))
+		}
+		for _, l := range req.Languages {
+			tag, err := language.Parse(l)
+			if err != nil {
+				return nil, customerrors.New(customerrors.CodeDataInvalid, "Неверный код языка: "+l)
+			}
+			rule.Languages = append(rule.Languages, tag.String())
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
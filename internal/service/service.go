@@ -2,12 +2,21 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"net/http"
+	"strings"
 
 	"github.com/OxytocinGroup/theca-v3/internal/config"
 	"github.com/OxytocinGroup/theca-v3/internal/model"
 	"github.com/OxytocinGroup/theca-v3/internal/repository"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/geoip"
 	jwtauth "github.com/OxytocinGroup/theca-v3/internal/utils/jwt"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/linkcheck"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/metadata"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/referrer"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/safehttp"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/useragent"
 	"github.com/OxytocinGroup/theca-v3/internal/vars"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -16,22 +25,179 @@ type Service interface {
 	Register(ctx context.Context, email, username, password string) error
 	Login(ctx context.Context, username, password string) (string, string, error)
 	LogoutFromAllSessions(ctx context.Context, userID uint) error
+
+	CreateLink(ctx context.Context, userID uint, req model.CreateLinkRequest) (*model.Link, error)
+	BulkCreateLinks(ctx context.Context, userID uint, rows []model.BulkLinkRow, atomic bool) (*model.BulkLinksResponse, error)
+	GetLink(ctx context.Context, userID, id uint) (*model.Link, error)
+	ListLinks(ctx context.Context, userID uint, filter model.LinkFilter, page model.PageRequest) ([]model.Link, string, error)
+	UpdateLink(ctx context.Context, userID, id uint, req model.UpdateLinkRequest) (*model.Link, error)
+	DeleteLink(ctx context.Context, userID, id uint) error
+	RestoreLink(ctx context.Context, userID, id uint) (*model.Link, error)
+	PurgeLink(ctx context.Context, userID, id uint) error
+	VariantStats(ctx context.Context, userID, linkID uint) ([]model.VariantStats, error)
+	LinkQRCode(ctx context.Context, userID, linkID uint, req model.QRCodeRequest) (*model.QRCode, error)
+	ListLinkRevisions(ctx context.Context, userID, linkID uint) ([]model.LinkRevision, error)
+	RollbackLink(ctx context.Context, userID, linkID, number uint) (*model.Link, error)
+	RedirectTarget(ctx context.Context, link *model.Link, visitor *model.Visitor) (*model.RedirectTarget, error)
+	ResolveLink(ctx context.Context, domain *model.Domain, code string) (*model.Link, error)
+	CheckLinkAvailable(ctx context.Context, link *model.Link) error
+	RecordClick(ctx context.Context, link *model.Link, visitor *model.Visitor, click *model.Click) error
+	ClickSummary(ctx context.Context, userID uint, filter model.StatsFilter) (*model.ClickSummary, error)
+	ClickTimeSeries(ctx context.Context, userID uint, req model.TimeSeriesRequest) (*model.TimeSeries, error)
+	LinkTimeSeries(ctx context.Context, userID, linkID uint, req model.TimeSeriesRequest) (*model.TimeSeries, error)
+	UniqueVisitors(ctx context.Context, userID uint, req model.VisitorsRequest) (*model.UniqueVisitors, error)
+	LinkUniqueVisitors(ctx context.Context, userID, linkID uint, req model.VisitorsRequest) (*model.UniqueVisitors, error)
+	RotateVisitorSalts(ctx context.Context) error
+	LinkReferrers(ctx context.Context, userID, linkID uint, req model.ReferrersRequest) (*model.ReferrerBreakdown, error)
+	AgentBreakdown(ctx context.Context, userID uint, req model.AgentsRequest) (*model.AgentBreakdown, error)
+	LinkAgents(ctx context.Context, userID, linkID uint, req model.AgentsRequest) (*model.AgentBreakdown, error)
+	ReapExpiredLinks(ctx context.Context) error
+	UnlockLink(ctx context.Context, link *model.Link, password string) (string, error)
+	VerifyLinkUnlock(link *model.Link, token string) bool
+
+	CreateCampaign(ctx context.Context, userID uint, req model.CreateCampaignRequest) (*model.Campaign, error)
+	GetCampaign(ctx context.Context, userID, id uint) (*model.Campaign, error)
+	ListCampaigns(ctx context.Context, userID uint) ([]model.Campaign, error)
+	UpdateCampaign(ctx context.Context, userID, id uint, req model.UpdateCampaignRequest) (*model.Campaign, error)
+	DeleteCampaign(ctx context.Context, userID, id uint) error
+	CampaignStats(ctx context.Context, userID, id uint, filter model.StatsFilter) (*model.ClickSummary, error)
+	CampaignReferrers(ctx context.Context, userID, id uint, req model.ReferrersRequest) (*model.ReferrerBreakdown, error)
+
+	CreateTag(ctx context.Context, userID uint, req model.CreateTagRequest) (*model.Tag, error)
+	ListTags(ctx context.Context, userID uint) ([]model.Tag, error)
+	UpdateTag(ctx context.Context, userID, id uint, req model.UpdateTagRequest) (*model.Tag, error)
+	DeleteTag(ctx context.Context, userID, id uint) error
+
+	CreateBookmark(ctx context.Context, userID uint, req model.CreateBookmarkRequest) (*model.Bookmark, error)
+	ListBookmarks(ctx context.Context, userID uint, page model.PageRequest) ([]model.Bookmark, string, error)
+	DeleteBookmark(ctx context.Context, userID, id uint) error
+	RestoreBookmark(ctx context.Context, userID, id uint) (*model.Bookmark, error)
+	PurgeBookmark(ctx context.Context, userID, id uint) error
+
+	ListTrash(ctx context.Context, userID uint) (*model.TrashResponse, error)
+	PurgeTrash(ctx context.Context) error
+	FetchMetadata(ctx context.Context) error
+	CheckHealth(ctx context.Context) error
+
+	CreateDomain(ctx context.Context, userID uint, req model.CreateDomainRequest) (*model.Domain, error)
+	GetDomain(ctx context.Context, userID, id uint) (*model.Domain, error)
+	ListDomains(ctx context.Context, userID uint) ([]model.Domain, error)
+	UpdateDomain(ctx context.Context, userID, id uint, req model.UpdateDomainRequest) (*model.Domain, error)
+	DeleteDomain(ctx context.Context, userID, id uint) error
+	VerifyDomain(ctx context.Context, userID, id uint) (*model.Domain, error)
+	ResolveDomain(ctx context.Context, host string) (*model.Domain, error)
+	ReapDomainClaims(ctx context.Context) error
 }
 
 type service struct {
-	repo repository.Repository
-	log  *slog.Logger
-	cfg  *config.Config
+	repo     repository.Repository
+	log      *slog.Logger
+	cfg      *config.Config
+	codes    CodeGenerator
+	resolver Resolver
+	geo      GeoLocator
+	agents   *useragent.Parser
+	clicks   ClickQueue
+	salt     visitorSalt
+	referrer *referrer.Classifier
+	http     *http.Client
+	metadata *metadata.Fetcher
+	checker  *linkcheck.Checker
+	reserved map[string]struct{}
+}
+
+// Option customizes a service built by NewService.
+type Option func(*service)
+
+// WithResolver replaces the DNS resolver used for domain verification.
+func WithResolver(resolver Resolver) Option {
+	return func(s *service) {
+		s.resolver = resolver
+	}
+}
+
+// WithGeoLocator replaces the country lookup used by targeting rules.
+func WithGeoLocator(geo GeoLocator) Option {
+	return func(s *service) {
+		s.geo = geo
+	}
+}
+
+// WithClickQueue has clicks written in the background through queue instead
+// of on the redirect path.
+func WithClickQueue(queue ClickQueue) Option {
+	return func(s *service) {
+		s.clicks = queue
+	}
 }
 
-func NewService(repo repository.Repository, log *slog.Logger, cfg *config.Config) Service {
-	return &service{
-		repo: repo,
-		log:  log,
-		cfg:  cfg,
+// WithHTTPClient replaces the client used to fetch user-supplied URLs, which
+// by default only connects to public addresses. It exists for tests that
+// serve pages from loopback; a client without that check lets users make
+// the server fetch from the internal network.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *service) {
+		s.http = client
 	}
 }
 
+// NewService builds the service. It fails on a short code config that would
+// leave links without usable codes.
+func NewService(repo repository.Repository, log *slog.Logger, cfg *config.Config, opts ...Option) (Service, error) {
+	reserved := make(map[string]struct{}, len(cfg.ReservedCodes))
+	for _, code := range cfg.ReservedCodes {
+		reserved[strings.ToLower(code)] = struct{}{}
+	}
+
+	codes, err := NewCodeGenerator(cfg.CodeStrategy, cfg.CodeLength, cfg.CodeSalt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid code generator config: %w", err)
+	}
+	if cfg.CodeMaxAttempts <= 0 {
+		return nil, fmt.Errorf("invalid code max attempts %d", cfg.CodeMaxAttempts)
+	}
+
+	var geo GeoLocator = noGeoLocator{}
+	if cfg.GeoIPDatabase != "" {
+		reader, err := geoip.Open(cfg.GeoIPDatabase)
+		if err != nil {
+			log.Warn("failed to open geoip database, country rules will not match", "path", cfg.GeoIPDatabase, "error", err)
+		} else {
+			geo = reader
+		}
+	}
+
+	agents := useragent.Default()
+	if cfg.UserAgentRules != "" {
+		parser, err := useragent.Load(cfg.UserAgentRules)
+		if err != nil {
+			log.Warn("failed to load user agent rules, using the built-in ones", "path", cfg.UserAgentRules, "error", err)
+		} else {
+			agents = parser
+		}
+	}
+
+	s := &service{
+		repo:     repo,
+		log:      log,
+		cfg:      cfg,
+		codes:    codes,
+		resolver: newResolver(cfg.DNSResolverAddr),
+		geo:      geo,
+		agents:   agents,
+		http:     safehttp.NewClient(cfg.FetchTimeout),
+		reserved: reserved,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.metadata = metadata.NewFetcher(s.http, cfg.MetadataMaxBytes, cfg.FetchHostLimit)
+	s.checker = linkcheck.NewChecker(s.http, cfg.FetchHostLimit)
+	s.referrer = s.newReferrerClassifier()
+
+	return s, nil
+}
+
 func (s *service) Register(ctx context.Context, email, username, password string) error {
 	const op = "service.Register"
 	log := s.log.With("op", op)
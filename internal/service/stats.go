@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+	// Time zones asked for by clients must resolve even on hosts without a
+	// zoneinfo database.
+	_ "time/tzdata"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+)
+
+// ClickSummary aggregates the clicks on the user's links matching filter.
+func (s *service) ClickSummary(ctx context.Context, userID uint, filter model.StatsFilter) (*model.ClickSummary, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Начало периода должно быть раньше его конца")
+	}
+	filter.Tags = normalizeTags(filter.Tags)
+
+	return s.repo.SummarizeClicks(ctx, userID, filter)
+}
+
+// LinkAgents breaks the clicks of one link down by browser, OS and device.
+func (s *service) LinkAgents(ctx context.Context, userID, linkID uint, req model.AgentsRequest) (*model.AgentBreakdown, error) {
+	link, err := s.repo.GetUserLink(ctx, userID, linkID)
+	if err != nil {
+		return nil, err
+	}
+
+	req.LinkFilter = model.LinkFilter{}
+	req.LinkID = link.ID
+	return s.AgentBreakdown(ctx, userID, req)
+}
+
+// AgentBreakdown breaks the clicks on the user's links matching the filter
+// down by browser, OS and device.
+func (s *service) AgentBreakdown(ctx context.Context, userID uint, req model.AgentsRequest) (*model.AgentBreakdown, error) {
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Начало периода должно быть раньше его конца")
+	}
+	req.Tags = normalizeTags(req.Tags)
+	if req.Limit == 0 {
+		req.Limit = model.DefaultAgentLimit
+	}
+
+	return s.repo.CountClicksByAgent(ctx, userID, req.StatsFilter, req.Versions, req.Limit)
+}
+
+// statsSlot is the granularity clicks are counted at in the database. Every
+// UTC offset in use is a multiple of 15 minutes, so slots never straddle a
+// bucket boundary in any time zone.
+const statsSlot = 15 * time.Minute
+
+// LinkTimeSeries buckets the clicks of one link over time.
+func (s *service) LinkTimeSeries(ctx context.Context, userID, linkID uint, req model.TimeSeriesRequest) (*model.TimeSeries, error) {
+	link, err := s.repo.GetUserLink(ctx, userID, linkID)
+	if err != nil {
+		return nil, err
+	}
+
+	req.LinkFilter = model.LinkFilter{}
+	req.LinkID = link.ID
+	return s.ClickTimeSeries(ctx, userID, req)
+}
+
+// ClickTimeSeries buckets the clicks on the user's links matching the
+// filter over time.
+func (s *service) ClickTimeSeries(ctx context.Context, userID uint, req model.TimeSeriesRequest) (*model.TimeSeries, error) {
+	if req.Interval == "" {
+		req.Interval = model.IntervalDay
+	}
+	if req.TZ == "" {
+		req.TZ = "UTC"
+	}
+	loc, err := time.LoadLocation(req.TZ)
+	if err != nil {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Неизвестный часовой пояс")
+	}
+
+	to := time.Now()
+	if req.To != nil {
+		to = *req.To
+	}
+	from := defaultSeriesStart(req.Interval, to.In(loc))
+	if req.From != nil {
+		from = *req.From
+	}
+	if !from.Before(to) {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Начало периода должно быть раньше его конца")
+	}
+
+	series := model.TimeSeries{
+		From:     bucketStart(req.Interval, from.In(loc)),
+		To:       to.In(loc),
+		Interval: req.Interval,
+		TZ:       loc.String(),
+		Buckets:  make([]model.ClickBucket, 0),
+	}
+	for start := series.From; start.Before(to); start = nextBucket(req.Interval, start) {
+		if len(series.Buckets) == model.MaxTimeSeriesBuckets {
+			return nil, customerrors.New(customerrors.CodeDataInvalid, "Слишком много интервалов, сократите период или увеличьте интервал")
+		}
+		series.Buckets = append(series.Buckets, model.ClickBucket{Start: start})
+	}
+
+	// Clicks are counted from the start of the first bucket, so it is not
+	// cut short when from falls inside it.
+	req.From, req.To = &series.From, &to
+	req.Tags = normalizeTags(req.Tags)
+	counts, err := s.repo.CountClicksBySlot(ctx, userID, req.StatsFilter, statsSlot)
+	if err != nil {
+		return nil, err
+	}
+
+	for slot, clicks := range counts {
+		t := time.Unix(slot, 0)
+		i := sort.Search(len(series.Buckets), func(i int) bool { return series.Buckets[i].Start.After(t) }) - 1
+		if i < 0 {
+			continue
+		}
+		series.Buckets[i].Clicks += clicks
+		series.Total += clicks
+	}
+
+	return &series, nil
+}
+
+// bucketStart returns the start of the bucket t falls into, in t's location.
+// Hours are cut by elapsed time rather than wall clock, so the repeated hour
+// when clocks go back stays a bucket of its own.
+func bucketStart(interval string, t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch interval {
+	case model.IntervalHour:
+		return t.Add(-time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	case model.IntervalWeek:
+		monday := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-monday, 0, 0, 0, 0, t.Location())
+	case model.IntervalMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// nextBucket returns the start of the bucket following the one starting at
+// start. Days follow the calendar, so they last 23 or 25 hours across DST
+// changes.
+func nextBucket(interval string, start time.Time) time.Time {
+	y, m, d := start.Date()
+	switch interval {
+	case model.IntervalHour:
+		return start.Add(time.Hour)
+	case model.IntervalWeek:
+		return time.Date(y, m, d+7, 0, 0, 0, 0, start.Location())
+	case model.IntervalMonth:
+		return time.Date(y, m+1, 1, 0, 0, 0, 0, start.Location())
+	default:
+		return time.Date(y, m, d+1, 0, 0, 0, 0, start.Location())
+	}
+}
+
+// defaultSeriesStart returns where a series ending at to starts when no
+// range was asked for.
+func defaultSeriesStart(interval string, to time.Time) time.Time {
+	switch interval {
+	case model.IntervalHour:
+		return to.Add(-24 * time.Hour)
+	case model.IntervalWeek:
+		return to.AddDate(0, 0, -7*12)
+	case model.IntervalMonth:
+		return to.AddDate(0, -12, 0)
+	default:
+		return to.AddDate(0, 0, -30)
+	}
+}
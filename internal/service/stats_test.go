@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+)
+
+// Clicks are stored in UTC while ranges come in the client's time zone; the
+// two must compare as instants rather than as SQLite text.
+func TestClickTimeSeriesTimeZone(t *testing.T) {
+	repo, db := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	link := model.Link{Code: "abc", Destination: "https://example.com/", UserID: 1, Enabled: true}
+	if err := db.Create(&link).Error; err != nil {
+		t.Fatal(err)
+	}
+	clicks := []model.Click{
+		// 08:30 and 23:30 on March 11 in Tokyo.
+		{LinkID: link.ID, CreatedAt: time.Date(2026, 3, 10, 23, 30, 0, 0, time.UTC)},
+		{LinkID: link.ID, CreatedAt: time.Date(2026, 3, 11, 14, 30, 0, 0, time.UTC)},
+		// 00:30 and 01:00 on March 12 in Tokyo.
+		{LinkID: link.ID, CreatedAt: time.Date(2026, 3, 11, 15, 30, 0, 0, time.UTC)},
+		{LinkID: link.ID, CreatedAt: time.Date(2026, 3, 11, 16, 0, 0, 0, time.UTC)},
+	}
+	if err := repo.CreateClicks(ctx, clicks); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewService(repo, discardLogger(), testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 3, 11, 0, 0, 0, 0, tokyo)
+	to := time.Date(2026, 3, 12, 0, 0, 0, 0, tokyo)
+	req := model.TimeSeriesRequest{TZ: "Asia/Tokyo", StatsFilter: model.StatsFilter{From: &from, To: &to}}
+
+	series, err := s.LinkTimeSeries(ctx, 1, link.ID, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series.Buckets) != 1 || series.Buckets[0].Clicks != 2 || series.Total != 2 {
+		t.Fatalf("got buckets %+v and total %d, want one bucket of 2 clicks", series.Buckets, series.Total)
+	}
+
+	summary, err := s.ClickSummary(ctx, 1, model.StatsFilter{From: &from, To: &to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Total != 2 {
+		t.Fatalf("got %d clicks in the summary, want 2", summary.Total)
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+)
+
+func (s *service) CreateTag(ctx context.Context, userID uint, req model.CreateTagRequest) (*model.Tag, error) {
+	const op = "service.CreateTag"
+	log := s.log.With("op", op)
+
+	name, err := s.availableTagName(ctx, userID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := model.Tag{
+		Name:   name,
+		UserID: userID,
+	}
+	if err := s.repo.CreateTag(ctx, &tag); err != nil {
+		return nil, err
+	}
+
+	log.Debug("tag created", "tag", tag.ID, "user", userID)
+	return &tag, nil
+}
+
+func (s *service) ListTags(ctx context.Context, userID uint) ([]model.Tag, error) {
+	return s.repo.ListUserTags(ctx, userID)
+}
+
+// UpdateTag renames a tag; the links carrying it follow along.
+func (s *service) UpdateTag(ctx context.Context, userID, id uint, req model.UpdateTagRequest) (*model.Tag, error) {
+	tag, err := s.repo.GetUserTag(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(req.Name) == tag.Name {
+		return tag, nil
+	}
+	if tag.Name, err = s.availableTagName(ctx, userID, req.Name); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveTag(ctx, tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// DeleteTag removes a tag from the account and from every link carrying it.
+func (s *service) DeleteTag(ctx context.Context, userID, id uint) error {
+	tag, err := s.repo.GetUserTag(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteTag(ctx, tag)
+}
+
+// availableTagName trims name and checks the user has no tag called that yet.
+func (s *service) availableTagName(ctx context.Context, userID uint, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", customerrors.New(customerrors.CodeDataInvalid, "Название тега не может быть пустым")
+	}
+
+	exists, err := s.repo.TagNameExists(ctx, userID, name)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", customerrors.New(customerrors.CodeDataConflict, "Тег с таким названием уже существует")
+	}
+	return name, nil
+}
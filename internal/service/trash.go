@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+)
+
+func (s *service) ListTrash(ctx context.Context, userID uint) (*model.TrashResponse, error) {
+	links, err := s.repo.ListDeletedLinks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	bookmarks, err := s.repo.ListDeletedBookmarks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TrashResponse{
+		Links:      links,
+		Bookmarks:  bookmarks,
+		PurgeAfter: s.cfg.TrashRetention.String(),
+	}, nil
+}
+
+// RestoreLink takes a link out of the trash. Its code stayed reserved while
+// it was trashed, so the link comes back under the same short URL.
+func (s *service) RestoreLink(ctx context.Context, userID, id uint) (*model.Link, error) {
+	const op = "service.RestoreLink"
+	log := s.log.With("op", op)
+
+	link, err := s.repo.GetDeletedUserLink(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RestoreLink(ctx, link); err != nil {
+		return nil, err
+	}
+
+	log.Debug("link restored", "link", link.ID, "user", userID)
+	return s.repo.GetUserLink(ctx, userID, link.ID)
+}
+
+func (s *service) PurgeLink(ctx context.Context, userID, id uint) error {
+	const op = "service.PurgeLink"
+	log := s.log.With("op", op)
+
+	link, err := s.repo.GetDeletedUserLink(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.PurgeLink(ctx, link); err != nil {
+		return err
+	}
+
+	log.Debug("link purged", "link", link.ID, "user", userID)
+	return nil
+}
+
+func (s *service) RestoreBookmark(ctx context.Context, userID, id uint) (*model.Bookmark, error) {
+	const op = "service.RestoreBookmark"
+	log := s.log.With("op", op)
+
+	bookmark, err := s.repo.GetDeletedUserBookmark(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RestoreBookmark(ctx, bookmark); err != nil {
+		return nil, err
+	}
+
+	log.Debug("bookmark restored", "bookmark", bookmark.ID, "user", userID)
+	return bookmark, nil
+}
+
+func (s *service) PurgeBookmark(ctx context.Context, userID, id uint) error {
+	const op = "service.PurgeBookmark"
+	log := s.log.With("op", op)
+
+	bookmark, err := s.repo.GetDeletedUserBookmark(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.PurgeBookmark(ctx, bookmark); err != nil {
+		return err
+	}
+
+	log.Debug("bookmark purged", "bookmark", bookmark.ID, "user", userID)
+	return nil
+}
+
+// PurgeTrash removes links and bookmarks that stayed in the trash longer
+// than the configured retention period.
+func (s *service) PurgeTrash(ctx context.Context) error {
+	const op = "service.PurgeTrash"
+	log := s.log.With("op", op)
+
+	before := time.Now().Add(-s.cfg.TrashRetention)
+
+	links, err := s.repo.PurgeDeletedLinks(ctx, before)
+	if err != nil {
+		return err
+	}
+	bookmarks, err := s.repo.PurgeDeletedBookmarks(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	if links > 0 || bookmarks > 0 {
+		log.Info("purged trash", "links", links, "bookmarks", bookmarks)
+	}
+	return nil
+}
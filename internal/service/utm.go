@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+)
+
+// RedirectTarget returns where a visitor is sent. A matching targeting rule
+// comes first, then the A/B split, then the link destination. The UTM presets
+// of the link and its campaign, loaded with the link, are added to the
+// result, link presets taking precedence over campaign presets parameter by
+// parameter. On iOS and Android
+// a deep link of the link then takes over, with the web target as fallback.
+func (s *service) RedirectTarget(ctx context.Context, link *model.Link, visitor *model.Visitor) (*model.RedirectTarget, error) {
+	target := &model.RedirectTarget{URL: link.Destination}
+	traits := s.traitsOf(visitor)
+
+	if rule := matchRule(link.Rules, traits); rule != nil {
+		target.URL = rule.Destination
+	} else if variant := chooseVariant(link.Variants, visitor.Variant); variant != nil {
+		target.URL = variant.Destination
+		target.Variant = variant.Label
+	}
+
+	utm := link.UTM
+	if link.Campaign != nil {
+		utm = mergeUTM(link.Campaign.UTM, utm)
+	}
+
+	destination, err := applyUTM(target.URL, utm, link.UTMOverride)
+	if err != nil {
+		return target, err
+	}
+	target.URL = destination
+
+	applyDeepLink(target, link.DeepLink, traits)
+	return target, nil
+}
+
+// mergeUTM overlays the non-empty presets of top onto base.
+func mergeUTM(base, top model.UTM) model.UTM {
+	pick := func(b, t string) string {
+		if t != "" {
+			return t
+		}
+		return b
+	}
+
+	return model.UTM{
+		Source:   pick(base.Source, top.Source),
+		Medium:   pick(base.Medium, top.Medium),
+		Campaign: pick(base.Campaign, top.Campaign),
+		Term:     pick(base.Term, top.Term),
+		Content:  pick(base.Content, top.Content),
+	}
+}
+
+// applyUTM appends the presets to the query of destination. Parameters the
+// destination already has are kept unless override is set, in which case
+// they are replaced. The rest of the query is left untouched, order included.
+func applyUTM(destination string, utm model.UTM, override bool) (string, error) {
+	params := [][2]string{
+		{"utm_source", utm.Source},
+		{"utm_medium", utm.Medium},
+		{"utm_campaign", utm.Campaign},
+		{"utm_term", utm.Term},
+		{"utm_content", utm.Content},
+	}
+
+	u, err := url.Parse(destination)
+	if err != nil {
+		return destination, err
+	}
+
+	var pairs []string
+	if u.RawQuery != "" {
+		pairs = strings.Split(u.RawQuery, "&")
+	}
+
+	changed := false
+	for _, p := range params {
+		key, value := p[0], p[1]
+		if value == "" {
+			continue
+		}
+
+		i := indexQueryKey(pairs, key)
+		if i >= 0 && !override {
+			continue
+		}
+		for ; i >= 0; i = indexQueryKey(pairs, key) {
+			pairs = append(pairs[:i], pairs[i+1:]...)
+		}
+		pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		changed = true
+	}
+	if !changed {
+		return destination, nil
+	}
+
+	u.RawQuery = strings.Join(pairs, "&")
+	return u.String(), nil
+}
+
+// indexQueryKey returns the index of the first raw query pair with the given
+// key, or -1.
+func indexQueryKey(pairs []string, key string) int {
+	for i, pair := range pairs {
+		name, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if name == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func normalizeUTM(utm model.UTM) model.UTM {
+	return model.UTM{
+		Source:   strings.TrimSpace(utm.Source),
+		Medium:   strings.TrimSpace(utm.Medium),
+		Campaign: strings.TrimSpace(utm.Campaign),
+		Term:     strings.TrimSpace(utm.Term),
+		Content:  strings.TrimSpace(utm.Content),
+	}
+}
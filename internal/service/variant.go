@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+)
+
+// buildLinkVariants validates variant requests and assigns default labels.
+// A split needs at least two variants; an empty list turns it off.
+func buildLinkVariants(reqs []model.LinkVariantRequest) ([]model.LinkVariant, error) {
+	if len(reqs) == 0 {
+		return []model.LinkVariant{}, nil
+	}
+	if len(reqs) == 1 {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Для A/B теста нужно минимум два варианта")
+	}
+
+	variants := make([]model.LinkVariant, 0, len(reqs))
+	seen := make(map[string]struct{}, len(reqs))
+	for i, req := range reqs {
+		if err := validateDestination(req.Destination); err != nil {
+			return nil, err
+		}
+
+		label := strings.ToUpper(req.Label)
+		if label == "" {
+			label = string(rune('A' + i))
+		}
+		if _, ok := seen[label]; ok {
+			return nil, customerrors.New(customerrors.CodeDataInvalid, "Метки вариантов должны быть уникальны")
+		}
+		seen[label] = struct{}{}
+
+		variants = append(variants, model.LinkVariant{
+			Label:       label,
+			Destination: req.Destination,
+			Weight:      req.Weight,
+			Position:    i,
+		})
+	}
+	return variants, nil
+}
+
+// chooseVariant keeps a visitor on the variant they were assigned before,
+// as long as it still exists, and otherwise draws one by weight.
+func chooseVariant(variants []model.LinkVariant, previous string) *model.LinkVariant {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	var total uint
+	for i := range variants {
+		if previous != "" && variants[i].Label == previous {
+			return &variants[i]
+		}
+		total += variants[i].Weight
+	}
+	if total == 0 {
+		return &variants[0]
+	}
+
+	n := rand.UintN(total)
+	for i := range variants {
+		if n < variants[i].Weight {
+			return &variants[i]
+		}
+		n -= variants[i].Weight
+	}
+	return &variants[len(variants)-1]
+}
+
+// VariantStats reports the clicks served by each variant of a link,
+// including labels that have since been removed from the split.
+func (s *service) VariantStats(ctx context.Context, userID, linkID uint) ([]model.VariantStats, error) {
+	link, err := s.repo.GetUserLink(ctx, userID, linkID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.CountLinkClicksByVariant(ctx, link.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]model.VariantStats, 0, len(link.Variants)+len(counts))
+	for _, v := range link.Variants {
+		stats = append(stats, model.VariantStats{
+			Label:       v.Label,
+			Destination: v.Destination,
+			Weight:      v.Weight,
+			Clicks:      counts[v.Label],
+		})
+		delete(counts, v.Label)
+	}
+
+	retired := make([]string, 0, len(counts))
+	for label := range counts {
+		if label != "" {
+			retired = append(retired, label)
+		}
+	}
+	sort.Strings(retired)
+	for _, label := range retired {
+		stats = append(stats, model.VariantStats{Label: label, Clicks: counts[label]})
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+	customerrors "github.com/OxytocinGroup/theca-v3/internal/utils/errors"
+	"github.com/OxytocinGroup/theca-v3/internal/utils/hll"
+)
+
+// maxVisitorsDays bounds the days unique visitors are counted over at once.
+const maxVisitorsDays = 366
+
+// visitorSalt caches the salts of the current and next UTC day, so a click
+// right after midnight finds its salt before the next rotation.
+type visitorSalt struct {
+	mu    sync.RWMutex
+	salts map[string][]byte
+}
+
+// visitorHash identifies a visitor for the UTC day of now by keying their
+// address and user agent with the day's salt. Once the salt is rotated out
+// the hash can no longer be tied to the address.
+func (s *service) visitorHash(visitor *model.Visitor, now time.Time) (string, error) {
+	if visitor.IP == nil {
+		return "", nil
+	}
+
+	salt, err := s.daySalt(now.UTC().Format(model.VisitorDayFormat))
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(visitor.IP.String()))
+	mac.Write([]byte{0})
+	mac.Write([]byte(visitor.UserAgent))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// daySalt returns the cached salt of day. It never touches the database, as
+// it runs on the redirect path; RotateVisitorSalts keeps the cache filled.
+func (s *service) daySalt(day string) ([]byte, error) {
+	s.salt.mu.RLock()
+	defer s.salt.mu.RUnlock()
+
+	salt, ok := s.salt.salts[day]
+	if !ok {
+		return nil, fmt.Errorf("no visitor salt for %s", day)
+	}
+	return salt, nil
+}
+
+// RotateVisitorSalts creates the salts of the current and next UTC day unless
+// another instance did, deletes those of earlier days and caches the two.
+func (s *service) RotateVisitorSalts(ctx context.Context) error {
+	return s.rotateVisitorSalts(ctx, time.Now().UTC())
+}
+
+func (s *service) rotateVisitorSalts(ctx context.Context, now time.Time) error {
+	salts := make([]model.VisitorSalt, 2)
+	for i := range salts {
+		salts[i] = model.VisitorSalt{Day: now.AddDate(0, 0, i).Format(model.VisitorDayFormat), Salt: make([]byte, 32)}
+		if _, err := rand.Read(salts[i].Salt); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.EnsureVisitorSalts(ctx, salts); err != nil {
+		return err
+	}
+
+	cache := make(map[string][]byte, len(salts))
+	for _, salt := range salts {
+		cache[salt.Day] = salt.Salt
+	}
+	s.salt.mu.Lock()
+	s.salt.salts = cache
+	s.salt.mu.Unlock()
+	return nil
+}
+
+// LinkUniqueVisitors estimates the distinct visitors of a link.
+func (s *service) LinkUniqueVisitors(ctx context.Context, userID, linkID uint, req model.VisitorsRequest) (*model.UniqueVisitors, error) {
+	link, err := s.repo.GetUserLink(ctx, userID, linkID)
+	if err != nil {
+		return nil, err
+	}
+
+	req.LinkFilter = model.LinkFilter{}
+	req.LinkID = link.ID
+	return s.UniqueVisitors(ctx, userID, req)
+}
+
+// UniqueVisitors estimates the distinct visitors across the user's links
+// matching the filter; a visitor of several links counts once.
+func (s *service) UniqueVisitors(ctx context.Context, userID uint, req model.VisitorsRequest) (*model.UniqueVisitors, error) {
+	to := time.Now().UTC()
+	if req.To != nil {
+		to = req.To.UTC()
+	}
+	from := to.AddDate(0, 0, -29)
+	if req.From != nil {
+		from = req.From.UTC()
+	}
+	if from.After(to) {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Начало периода должно быть не позже его конца")
+	}
+	if to.Sub(from) >= maxVisitorsDays*24*time.Hour {
+		return nil, customerrors.New(customerrors.CodeDataInvalid, "Период не может быть длиннее года")
+	}
+
+	req.From, req.To = &from, &to
+	req.Tags = normalizeTags(req.Tags)
+	sketches, err := s.repo.ListVisitorSketches(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	union := hll.New()
+	for _, data := range sketches {
+		sketch, err := hll.Unmarshal(data)
+		if err != nil {
+			s.log.Warn("skipping unreadable visitor sketch", "error", err)
+			continue
+		}
+		union.Merge(sketch)
+	}
+
+	return &model.UniqueVisitors{
+		From:     from.Format(model.VisitorDayFormat),
+		To:       to.Format(model.VisitorDayFormat),
+		Visitors: union.Count(),
+	}, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/model"
+)
+
+func TestVisitorHashRotatesDaily(t *testing.T) {
+	repo, db := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	newService := func() *service {
+		s, err := NewService(repo, discardLogger(), testConfig())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.(*service)
+	}
+	a, b := newService(), newService()
+
+	visitor := &model.Visitor{IP: net.ParseIP("203.0.113.7"), UserAgent: "Mozilla/5.0"}
+	morning := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	evening := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+	nextDay := time.Date(2026, 3, 2, 0, 1, 0, 0, time.UTC)
+
+	hash := func(s *service, v *model.Visitor, now time.Time) string {
+		t.Helper()
+		h, err := s.visitorHash(v, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	rotate := func(s *service, now time.Time) {
+		t.Helper()
+		if err := s.rotateVisitorSalts(ctx, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := a.visitorHash(visitor, morning); err == nil {
+		t.Fatal("hashed a visitor before any salt was loaded")
+	}
+	rotate(a, morning)
+	rotate(b, evening)
+
+	first := hash(a, visitor, morning)
+	if first == "" {
+		t.Fatal("empty hash for a visitor with an address")
+	}
+	if hash(a, visitor, evening) != first {
+		t.Fatal("the hash changed within a day")
+	}
+	if hash(b, visitor, evening) != first {
+		t.Fatal("instances sharing the database disagree on the hash")
+	}
+	other := &model.Visitor{IP: visitor.IP, UserAgent: "curl/8.0"}
+	if hash(a, other, morning) == first {
+		t.Fatal("visitors with different user agents share a hash")
+	}
+
+	// The next day's salt is cached ahead of the rotation after midnight.
+	next := hash(a, visitor, nextDay)
+	if next == first {
+		t.Fatal("the hash did not change with the day")
+	}
+	if hash(b, visitor, nextDay) != next {
+		t.Fatal("instances sharing the database disagree on the next day's hash")
+	}
+
+	rotate(a, nextDay)
+	if hash(a, visitor, nextDay) != next {
+		t.Fatal("the rotation replaced the next day's salt")
+	}
+	if _, err := a.visitorHash(visitor, evening); err == nil {
+		t.Fatal("the previous day's salt is still cached")
+	}
+	var days []string
+	if err := db.Model(&model.VisitorSalt{}).Order("day").Pluck("day", &days).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 2 || days[0] != "2026-03-02" || days[1] != "2026-03-03" {
+		t.Fatalf("salts of days %v are kept, want 2026-03-02 and 2026-03-03", days)
+	}
+
+	if h := hash(a, &model.Visitor{}, morning); h != "" {
+		t.Fatalf("got hash %q for a visitor without an address", h)
+	}
+}
@@ -29,6 +29,9 @@ const (
 	CodeDataNotFound ErrorCode = "DATA_NOT_FOUND"
 	CodeDataInvalid  ErrorCode = "DATA_INVALID"
 	CodeDataConflict ErrorCode = "DATA_CONFLICT"
+
+	// Коды ошибок для коротких ссылок
+	CodeLinkGone ErrorCode = "LINK_GONE"
 )
 
 // HTTPStatusMapping сопоставляет коды ошибок с HTTP-статусами
@@ -52,6 +55,9 @@ var HTTPStatusMapping = map[ErrorCode]int{
 	CodeDataNotFound: http.StatusNotFound,
 	CodeDataInvalid:  http.StatusBadRequest,
 	CodeDataConflict: http.StatusConflict,
+
+	// Коды для коротких ссылок
+	CodeLinkGone: http.StatusGone,
 }
 
 // APIError представляет структуру ошибки для API ответов
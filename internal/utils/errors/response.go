@@ -9,9 +9,10 @@ import (
 
 // Response представляет стандартный формат ответа API
 type Response struct {
-	Data    any       `json:"data,omitempty"`
-	Error   *APIError `json:"error,omitempty"`
-	Success bool      `json:"success"`
+	Data       any       `json:"data,omitempty"`
+	Error      *APIError `json:"error,omitempty"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Success    bool      `json:"success"`
 }
 
 // SuccessResponse создает успешный ответ с данными
@@ -24,6 +25,14 @@ func SuccessResponse(data any) Response {
 
 // ErrorResponse создает ответ с ошибкой
 func ErrorResponse(err error) Response {
+	return Response{
+		Success: false,
+		Error:   ToAPIError(err),
+	}
+}
+
+// ToAPIError конвертирует любую ошибку в APIError для ответа клиенту
+func ToAPIError(err error) *APIError {
 	var customErr *Error
 	var apiErr APIError
 
@@ -38,10 +47,7 @@ func ErrorResponse(err error) Response {
 		}
 	}
 
-	return Response{
-		Success: false,
-		Error:   &apiErr,
-	}
+	return &apiErr
 }
 
 // RespondWithError отправляет ответ с ошибкой через gin.Context
@@ -63,7 +69,29 @@ func RespondWithError(c *gin.Context, err error) {
 	c.JSON(statusCode, response)
 }
 
+// RespondWithErrorData отправляет ответ с ошибкой и дополнительными данными,
+// например результатами по каждой строке пакетного запроса
+func RespondWithErrorData(c *gin.Context, err error, data any) {
+	statusCode := http.StatusInternalServerError
+	var customErr *Error
+	if errors.As(err, &customErr) {
+		statusCode = customErr.GetHTTPStatus()
+	}
+
+	response := ErrorResponse(err)
+	response.Data = data
+	c.JSON(statusCode, response)
+}
+
 // RespondWithSuccess отправляет успешный ответ через gin.Context
 func RespondWithSuccess(c *gin.Context, data any) {
 	c.JSON(http.StatusOK, SuccessResponse(data))
 }
+
+// RespondWithPage отправляет страницу списка вместе с курсором следующей
+// страницы; на последней странице курсор пустой
+func RespondWithPage(c *gin.Context, data any, nextCursor string) {
+	response := SuccessResponse(data)
+	response.NextCursor = nextCursor
+	c.JSON(http.StatusOK, response)
+}
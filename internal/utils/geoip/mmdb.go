@@ -0,0 +1,381 @@
+// Package geoip reads MaxMind DB (MMDB) files such as GeoLite2-Country, so
+// country lookups work offline without a third-party dependency.
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strings"
+)
+
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// metadataMaxSize bounds how far from the end of the file the metadata marker
+// is searched for, as the format specification allows.
+const metadataMaxSize = 128 * 1024
+
+// maxDepth bounds how deeply maps, arrays and pointers may nest in the data
+// section, so a corrupt or hostile file cannot recurse without end.
+const maxDepth = 512
+
+var (
+	ErrInvalidDatabase = errors.New("geoip: invalid MaxMind database")
+	ErrNotFound        = errors.New("geoip: address not found")
+)
+
+// Reader looks up addresses in an MMDB file held in memory. It is safe for
+// concurrent use.
+type Reader struct {
+	buf        []byte
+	data       []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	ipv4Start  uint
+}
+
+// Open reads the database at path into memory.
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(buf)
+}
+
+// FromBytes parses a database already held in memory.
+func FromBytes(buf []byte) (*Reader, error) {
+	start := 0
+	if len(buf) > metadataMaxSize {
+		start = len(buf) - metadataMaxSize
+	}
+	i := bytes.LastIndex(buf[start:], metadataMarker)
+	if i < 0 {
+		return nil, ErrInvalidDatabase
+	}
+	metaStart := start + i + len(metadataMarker)
+
+	meta, _, err := (&decoder{buf: buf[metaStart:]}).decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: decode metadata: %w", err)
+	}
+	fields, ok := meta.(map[string]any)
+	if !ok {
+		return nil, ErrInvalidDatabase
+	}
+
+	r := &Reader{
+		buf:        buf,
+		nodeCount:  metaUint(fields, "node_count"),
+		recordSize: metaUint(fields, "record_size"),
+		ipVersion:  metaUint(fields, "ip_version"),
+	}
+	switch r.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("geoip: unsupported record size %d", r.recordSize)
+	}
+
+	nodeSize := r.recordSize / 4
+	if r.nodeCount == 0 || r.nodeCount > uint(metaStart)/nodeSize {
+		return nil, ErrInvalidDatabase
+	}
+	dataStart := r.nodeCount*nodeSize + 16
+	if dataStart > uint(metaStart-len(metadataMarker)) {
+		return nil, ErrInvalidDatabase
+	}
+	r.data = buf[dataStart : metaStart-len(metadataMarker)]
+
+	// IPv4 addresses live under ::/96 in IPv6 databases; find that subtree
+	// once instead of walking 96 zero bits on every lookup.
+	if r.ipVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < r.nodeCount; i++ {
+			if node, err = r.record(node, 0); err != nil {
+				return nil, err
+			}
+		}
+		r.ipv4Start = node
+	}
+
+	return r, nil
+}
+
+// Lookup returns the decoded record for ip, usually a map[string]any.
+func (r *Reader) Lookup(ip net.IP) (any, error) {
+	node, bits := uint(0), 128
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+		bits = 32
+		if r.ipVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else if r.ipVersion == 4 || len(ip) != net.IPv6len {
+		return nil, ErrNotFound
+	}
+
+	var err error
+	for i := 0; i < bits && node < r.nodeCount; i++ {
+		bit := uint(ip[i>>3]>>(7-uint(i&7))) & 1
+		if node, err = r.record(node, bit); err != nil {
+			return nil, err
+		}
+	}
+	if node <= r.nodeCount {
+		return nil, ErrNotFound
+	}
+
+	offset := node - r.nodeCount - 16
+	if offset >= uint(len(r.data)) {
+		return nil, ErrInvalidDatabase
+	}
+	value, _, err := (&decoder{buf: r.data}).decode(offset, 0)
+	return value, err
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country ip is located
+// in, falling back to the registered country of its network.
+func (r *Reader) Country(ip net.IP) (string, error) {
+	value, err := r.Lookup(ip)
+	if err != nil {
+		return "", err
+	}
+	record, _ := value.(map[string]any)
+
+	for _, key := range []string{"country", "registered_country"} {
+		country, _ := record[key].(map[string]any)
+		if code, _ := country["iso_code"].(string); code != "" {
+			return strings.ToUpper(code), nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// record reads the left (bit 0) or right (bit 1) pointer of a tree node.
+func (r *Reader) record(node, bit uint) (uint, error) {
+	nodeSize := r.recordSize / 4
+	if node >= r.nodeCount || (node+1)*nodeSize > uint(len(r.buf)) {
+		return 0, ErrInvalidDatabase
+	}
+	b := r.buf[node*nodeSize : (node+1)*nodeSize]
+
+	switch r.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6]), nil
+	default:
+		return uint(binary.BigEndian.Uint32(b[bit*4:])), nil
+	}
+}
+
+func metaUint(fields map[string]any, key string) uint {
+	v, _ := fields[key].(uint64)
+	return uint(v)
+}
+
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+// decoder decodes the MMDB data section format. Pointers are offsets into
+// buf; integers of every width decode to uint64 (int32 to int64), and
+// uint128 values to their big-endian bytes.
+type decoder struct {
+	buf []byte
+}
+
+// decode decodes the value at offset, which is nested depth levels deep,
+// and returns it with the offset of the value after it.
+func (d *decoder) decode(offset, depth uint) (any, uint, error) {
+	if depth > maxDepth {
+		return nil, 0, ErrInvalidDatabase
+	}
+	typ, size, offset, err := d.control(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typ == typePointer {
+		target, next, err := d.pointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		// The format does not allow a pointer to point at another pointer.
+		if target < uint(len(d.buf)) && int(d.buf[target]>>5) == typePointer {
+			return nil, 0, ErrInvalidDatabase
+		}
+		value, _, err := d.decode(target, depth+1)
+		return value, next, err
+	}
+
+	switch typ {
+	case typeMap, typeArray:
+		// Every entry takes at least a byte, which bounds what a corrupt
+		// size can make us allocate.
+		if size > uint(len(d.buf))-offset {
+			return nil, 0, ErrInvalidDatabase
+		}
+	}
+
+	switch typ {
+	case typeMap:
+		m := make(map[string]any, size)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, 0, ErrInvalidDatabase
+			}
+			if m[name], offset, err = d.decode(next, depth+1); err != nil {
+				return nil, 0, err
+			}
+		}
+		return m, offset, nil
+	case typeArray:
+		a := make([]any, size)
+		for i := range a {
+			if a[i], offset, err = d.decode(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+		}
+		return a, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	end := offset + size
+	if end > uint(len(d.buf)) {
+		return nil, 0, ErrInvalidDatabase
+	}
+	b := d.buf[offset:end]
+
+	switch typ {
+	case typeString:
+		return string(b), end, nil
+	case typeBytes, typeUint128:
+		return append([]byte(nil), b...), end, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), end, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), end, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return uintFromBytes(b), end, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return int64(int32(uintFromBytes(b))), end, nil
+	default:
+		return nil, 0, fmt.Errorf("geoip: unsupported data type %d", typ)
+	}
+}
+
+// control reads a control byte and returns the field type, its payload size
+// and the offset of the payload.
+func (d *decoder) control(offset uint) (int, uint, uint, error) {
+	if offset >= uint(len(d.buf)) {
+		return 0, 0, 0, ErrInvalidDatabase
+	}
+	ctrl := d.buf[offset]
+	offset++
+
+	typ := int(ctrl >> 5)
+	if typ == typeExtended {
+		if offset >= uint(len(d.buf)) {
+			return 0, 0, 0, ErrInvalidDatabase
+		}
+		typ = 7 + int(d.buf[offset])
+		offset++
+	}
+	if typ == typePointer {
+		return typ, uint(ctrl & 0x1f), offset, nil
+	}
+
+	size := uint(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		if offset+n > uint(len(d.buf)) {
+			return 0, 0, 0, ErrInvalidDatabase
+		}
+		extra := uintFromBytes(d.buf[offset : offset+n])
+		offset += n
+		switch n {
+		case 1:
+			size = 29 + uint(extra)
+		case 2:
+			size = 285 + uint(extra)
+		default:
+			size = 65821 + uint(extra)
+		}
+	}
+	return typ, size, offset, nil
+}
+
+// pointer resolves a pointer whose control bits are ctrl, returning the
+// target offset and the offset after the pointer itself.
+func (d *decoder) pointer(ctrl, offset uint) (uint, uint, error) {
+	n := (ctrl>>3)&0x3 + 1
+	if offset+n > uint(len(d.buf)) {
+		return 0, 0, ErrInvalidDatabase
+	}
+	b := uint(uintFromBytes(d.buf[offset : offset+n]))
+	v := ctrl & 0x7
+
+	var target uint
+	switch n {
+	case 1:
+		target = v<<8 | b
+	case 2:
+		target = (v<<16 | b) + 2048
+	case 3:
+		target = (v<<24 | b) + 526336
+	default:
+		target = b
+	}
+	return target, offset + n, nil
+}
+
+func uintFromBytes(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
@@ -0,0 +1,227 @@
+package geoip
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+// fixture builds a small IPv6 MMDB file with 24-bit records.
+type fixture struct {
+	// nodes hold their two records: a node index, -1 for no data or
+	// -2-offset for the value at offset in data.
+	nodes [][2]int
+	data  []byte
+}
+
+func newFixture() *fixture {
+	return &fixture{nodes: [][2]int{{-1, -1}}}
+}
+
+// insert maps a network to a value already encoded into the data section.
+func (f *fixture) insert(cidr string, value []byte) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	ip := network.IP
+	ones, _ := network.Mask.Size()
+	if v4 := ip.To4(); v4 != nil {
+		// IPv4 networks live under ::/96.
+		ip = append(make(net.IP, 12), v4...)
+		ones += 96
+	}
+
+	offset := len(f.data)
+	f.data = append(f.data, value...)
+
+	node := 0
+	for i := 0; i < ones; i++ {
+		bit := int(ip[i/8]>>(7-i%8)) & 1
+		if i == ones-1 {
+			f.nodes[node][bit] = -2 - offset
+			break
+		}
+		next := f.nodes[node][bit]
+		if next < 0 {
+			f.nodes = append(f.nodes, [2]int{-1, -1})
+			next = len(f.nodes) - 1
+			f.nodes[node][bit] = next
+		}
+		node = next
+	}
+}
+
+func (f *fixture) bytes() []byte {
+	count := len(f.nodes)
+	var buf []byte
+	for _, n := range f.nodes {
+		for _, rec := range n {
+			v := rec
+			switch {
+			case rec == -1:
+				v = count
+			case rec < -1:
+				v = count + 16 + (-2 - rec)
+			}
+			buf = append(buf, byte(v>>16), byte(v>>8), byte(v))
+		}
+	}
+	buf = append(buf, make([]byte, 16)...)
+	buf = append(buf, f.data...)
+	buf = append(buf, metadataMarker...)
+	return append(buf, encodeMap(
+		"node_count", encodeUint(uint64(count)),
+		"record_size", encodeUint(24),
+		"ip_version", encodeUint(6),
+	)...)
+}
+
+func encodeString(s string) []byte {
+	return append([]byte{typeString<<5 | byte(len(s))}, s...)
+}
+
+func encodeUint(v uint64) []byte {
+	b := []byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+	return append([]byte{typeUint32<<5 | 4}, b...)
+}
+
+// encodeMap encodes alternating keys and encoded values.
+func encodeMap(pairs ...any) []byte {
+	b := []byte{typeMap<<5 | byte(len(pairs)/2)}
+	for i := 0; i < len(pairs); i += 2 {
+		b = append(b, encodeString(pairs[i].(string))...)
+		b = append(b, pairs[i+1].([]byte)...)
+	}
+	return b
+}
+
+func country(key, code string) []byte {
+	return encodeMap(key, encodeMap("iso_code", encodeString(code)))
+}
+
+func testDatabase() []byte {
+	f := newFixture()
+	f.insert("1.2.3.0/24", country("country", "de"))
+	f.insert("2001:db8::/32", country("registered_country", "FR"))
+	return f.bytes()
+}
+
+func TestCountry(t *testing.T) {
+	r, err := FromBytes(testDatabase())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ip      string
+		want    string
+		wantErr error
+	}{
+		{"1.2.3.4", "DE", nil},
+		{"1.2.3.255", "DE", nil},
+		{"::ffff:1.2.3.4", "DE", nil},
+		{"2001:db8::1", "FR", nil},
+		{"1.2.4.1", "", ErrNotFound},
+		{"8.8.8.8", "", ErrNotFound},
+		{"::1", "", ErrNotFound},
+		{"2001:db9::1", "", ErrNotFound},
+	}
+	for _, tt := range tests {
+		got, err := r.Country(net.ParseIP(tt.ip))
+		if !errors.Is(err, tt.wantErr) || got != tt.want {
+			t.Errorf("Country(%s) = %q, %v; want %q, %v", tt.ip, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	if _, err := r.Country(nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Country(nil) error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestCorruptDatabase(t *testing.T) {
+	valid := testDatabase()
+	markerAt := len(valid) - len(encodeMap("node_count", encodeUint(0), "record_size", encodeUint(24), "ip_version", encodeUint(6))) - len(metadataMarker)
+
+	withMetadata := func(nodeCount uint64, recordSize uint64) []byte {
+		b := append([]byte(nil), valid[:markerAt+len(metadataMarker)]...)
+		return append(b, encodeMap(
+			"node_count", encodeUint(nodeCount),
+			"record_size", encodeUint(recordSize),
+			"ip_version", encodeUint(6),
+		)...)
+	}
+
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{"empty", nil},
+		{"no metadata", valid[:markerAt]},
+		{"truncated metadata", valid[:len(valid)-3]},
+		{"node count past the end", withMetadata(1<<20, 24)},
+		{"overflowing node count", withMetadata(1<<62, 24)},
+		{"unsupported record size", withMetadata(1, 16)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := FromBytes(tt.buf); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestCorruptData(t *testing.T) {
+	tests := []struct {
+		name  string
+		value []byte
+	}{
+		// A pointer (type 1, one byte long) to itself.
+		{"pointer cycle", []byte{typePointer << 5, 0}},
+		// A map whose only value points back at the map.
+		{"map cycle", append(append([]byte{typeMap<<5 | 1}, encodeString("a")...), typePointer<<5, 0)},
+		{"pointer past the end", []byte{typePointer<<5 | 0x7, 0xff}},
+		{"string past the end", []byte{typeString<<5 | 20, 'x'}},
+		{"huge array", []byte{0<<5 | 30, typeArray - 7, 0xff, 0xff}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			f.insert("1.2.3.0/24", tt.value)
+			r, err := FromBytes(f.bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Lookup(net.ParseIP("1.2.3.4")); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+// TestCorruptDatabaseNoPanic flips every byte of the fixture and truncates it
+// at every length; lookups may fail but must not panic.
+func TestCorruptDatabaseNoPanic(t *testing.T) {
+	valid := testDatabase()
+	ips := []net.IP{net.ParseIP("1.2.3.4"), net.ParseIP("2001:db8::1"), net.ParseIP("::1")}
+
+	check := func(buf []byte) {
+		r, err := FromBytes(buf)
+		if err != nil {
+			return
+		}
+		for _, ip := range ips {
+			_, _ = r.Country(ip)
+		}
+	}
+
+	for i := range valid {
+		check(valid[:i])
+		for _, v := range []byte{0x00, 0xff, valid[i] ^ 0x80, valid[i] + 1} {
+			buf := append([]byte(nil), valid...)
+			buf[i] = v
+			check(buf)
+		}
+	}
+}
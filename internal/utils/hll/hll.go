@@ -0,0 +1,142 @@
+// Package hll implements HyperLogLog sketches, which estimate the number of
+// distinct items added to them in a fixed amount of memory. Sketches merge
+// losslessly, so counts over several sketches come from merging them.
+package hll
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+)
+
+// Precision is the number of hash bits that select a register. The standard
+// error of an estimate is 1.04/sqrt(2^Precision), about 0.8%.
+const Precision = 14
+
+const registers = 1 << Precision
+
+const (
+	formatVersion = 1
+	formatDense   = 0
+	formatSparse  = 1
+	headerSize    = 3
+)
+
+var ErrInvalidSketch = errors.New("hll: invalid sketch")
+
+// Sketch is a HyperLogLog sketch. The zero value is not usable; create
+// sketches with New or Unmarshal.
+type Sketch struct {
+	registers []uint8
+}
+
+func New() *Sketch {
+	return &Sketch{registers: make([]uint8, registers)}
+}
+
+// Add adds an item to the sketch.
+func (s *Sketch) Add(item []byte) {
+	sum := sha256.Sum256(item)
+	s.AddHash(binary.BigEndian.Uint64(sum[:8]))
+}
+
+// AddHash adds an item by its 64-bit hash, which must be uniformly
+// distributed.
+func (s *Sketch) AddHash(hash uint64) {
+	i := hash >> (64 - Precision)
+	// The guard bit caps the run of leading zeros for all-zero remainders.
+	w := hash<<Precision | 1<<(Precision-1)
+	rank := uint8(bits.LeadingZeros64(w) + 1)
+	if rank > s.registers[i] {
+		s.registers[i] = rank
+	}
+}
+
+// Merge folds other into s, after which s counts the union of both.
+func (s *Sketch) Merge(other *Sketch) {
+	for i, r := range other.registers {
+		if r > s.registers[i] {
+			s.registers[i] = r
+		}
+	}
+}
+
+// Count estimates the number of distinct items added to the sketch.
+func (s *Sketch) Count() uint64 {
+	sum, zeros := 0.0, 0
+	for _, r := range s.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	m := float64(registers)
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	// The raw estimate is biased for small cardinalities, where counting
+	// the empty registers is more accurate.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// Marshal encodes the sketch. Sketches with few set registers, as most
+// sketches of a single day are, are stored as a list of them.
+func (s *Sketch) Marshal() []byte {
+	set := 0
+	for _, r := range s.registers {
+		if r != 0 {
+			set++
+		}
+	}
+
+	if set*3 < registers {
+		buf := make([]byte, headerSize, headerSize+set*3)
+		buf[0], buf[1], buf[2] = formatVersion, Precision, formatSparse
+		for i, r := range s.registers {
+			if r != 0 {
+				buf = binary.BigEndian.AppendUint16(buf, uint16(i))
+				buf = append(buf, r)
+			}
+		}
+		return buf
+	}
+
+	buf := make([]byte, headerSize, headerSize+registers)
+	buf[0], buf[1], buf[2] = formatVersion, Precision, formatDense
+	return append(buf, s.registers...)
+}
+
+// Unmarshal decodes a sketch encoded by Marshal.
+func Unmarshal(data []byte) (*Sketch, error) {
+	if len(data) < headerSize || data[0] != formatVersion || data[1] != Precision {
+		return nil, ErrInvalidSketch
+	}
+
+	s := New()
+	body := data[headerSize:]
+	switch data[2] {
+	case formatDense:
+		if len(body) != registers {
+			return nil, ErrInvalidSketch
+		}
+		copy(s.registers, body)
+	case formatSparse:
+		if len(body)%3 != 0 {
+			return nil, ErrInvalidSketch
+		}
+		for ; len(body) > 0; body = body[3:] {
+			i := binary.BigEndian.Uint16(body)
+			if int(i) >= registers {
+				return nil, ErrInvalidSketch
+			}
+			s.registers[i] = body[2]
+		}
+	default:
+		return nil, ErrInvalidSketch
+	}
+	return s, nil
+}
@@ -0,0 +1,97 @@
+package hll
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func item(i int) []byte {
+	return []byte("visitor-" + strconv.Itoa(i))
+}
+
+func TestCountAccuracy(t *testing.T) {
+	for _, n := range []int{1000, 10000, 100000} {
+		s := New()
+		for i := range n {
+			s.Add(item(i))
+			// Repeats must not count.
+			s.Add(item(i / 2))
+		}
+
+		got := s.Count()
+		if e := math.Abs(float64(got)-float64(n)) / float64(n); e > 0.03 {
+			t.Errorf("%d distinct items counted as %d, off by %.1f%%", n, got, e*100)
+		}
+	}
+}
+
+func TestCountEmpty(t *testing.T) {
+	if got := New().Count(); got != 0 {
+		t.Fatalf("empty sketch counted %d items", got)
+	}
+}
+
+func TestMergeIsUnion(t *testing.T) {
+	a, b, union := New(), New(), New()
+	for i := range 30000 {
+		a.Add(item(i))
+		union.Add(item(i))
+	}
+	for i := 20000; i < 50000; i++ {
+		b.Add(item(i))
+		union.Add(item(i))
+	}
+
+	a.Merge(b)
+	if !bytes.Equal(a.Marshal(), union.Marshal()) {
+		t.Fatal("merged sketch differs from the sketch of the union")
+	}
+	if got := a.Count(); math.Abs(float64(got)-50000)/50000 > 0.03 {
+		t.Fatalf("union of 50000 items counted as %d", got)
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	// 100 items are stored sparse, 100000 dense.
+	for _, n := range []int{0, 100, 100000} {
+		s := New()
+		for i := range n {
+			s.Add(item(i))
+		}
+
+		data := s.Marshal()
+		got, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("%d items: %v", n, err)
+		}
+		if !bytes.Equal(got.registers, s.registers) {
+			t.Fatalf("%d items: registers changed in a round trip", n)
+		}
+		if wantSparse := n < 1000; (data[2] == formatSparse) != wantSparse {
+			t.Errorf("%d items: encoded with format %d", n, data[2])
+		}
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"unknown version", []byte{2, Precision, formatSparse}},
+		{"other precision", []byte{formatVersion, 12, formatSparse}},
+		{"unknown format", []byte{formatVersion, Precision, 7}},
+		{"short dense body", []byte{formatVersion, Precision, formatDense, 1}},
+		{"partial sparse entry", []byte{formatVersion, Precision, formatSparse, 0, 1}},
+		{"register out of range", []byte{formatVersion, Precision, formatSparse, 0xff, 0xff, 1}},
+	}
+	for _, tt := range tests {
+		if _, err := Unmarshal(tt.data); !errors.Is(err, ErrInvalidSketch) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, ErrInvalidSketch)
+		}
+	}
+}
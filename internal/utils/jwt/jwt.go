@@ -43,3 +43,39 @@ func GenerateRefreshToken(userID, tokenVersion uint, refreshSecret []byte) (stri
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(refreshSecret)
 }
+
+type LinkUnlockClaims struct {
+	jwt.RegisteredClaims
+	// PasswordTag ties the token to the password it was issued for, so
+	// changing the password invalidates earlier unlocks.
+	PasswordTag string `json:"pwd"`
+	LinkID      uint   `json:"linkId"`
+}
+
+func GenerateLinkUnlockToken(linkID uint, passwordTag string, ttl time.Duration, secret []byte) (string, error) {
+	claims := LinkUnlockClaims{
+		LinkID:      linkID,
+		PasswordTag: passwordTag,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(secret)
+}
+
+func ParseLinkUnlockToken(tokenStr string, secret []byte) (*LinkUnlockClaims, error) {
+	var claims LinkUnlockClaims
+	_, err := jwt.ParseWithClaims(tokenStr, &claims, func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
@@ -0,0 +1,127 @@
+// Package linkcheck tests whether a URL still leads to a working page.
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/OxytocinGroup/theca-v3/internal/utils/safehttp"
+)
+
+const (
+	maxRedirects = 10
+	userAgent    = "Mozilla/5.0 (compatible; viabot/1.0; +https://via.oxytocingroup.com)"
+	// drainLimit bounds how much of a response body is read so the
+	// connection can be reused.
+	drainLimit = 4096
+)
+
+// Result is the outcome of a check. Chain lists the URLs redirected to, in
+// order; StatusCode is that of the last response.
+type Result struct {
+	Chain      []string
+	Error      string
+	StatusCode int
+	Latency    time.Duration
+	OK         bool
+}
+
+// Checker sends HEAD requests, falling back to a ranged GET for servers that
+// reject or mishandle HEAD. It is safe for concurrent use.
+type Checker struct {
+	client *http.Client
+	hosts  *safehttp.HostLimiter
+}
+
+// NewChecker wraps client, following redirects itself so the chain can be
+// recorded. At most perHost checks run against the same host at a time.
+func NewChecker(client *http.Client, perHost int) *Checker {
+	c := *client
+	c.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &Checker{client: &c, hosts: safehttp.NewHostLimiter(perHost)}
+}
+
+// Check reports whether rawURL answers with a 2xx status once redirects are
+// followed.
+func (c *Checker) Check(ctx context.Context, rawURL string) Result {
+	start := time.Now()
+	res := c.follow(ctx, http.MethodHead, rawURL)
+	if !res.OK && ctx.Err() == nil {
+		start = time.Now()
+		res = c.follow(ctx, http.MethodGet, rawURL)
+	}
+	res.Latency = time.Since(start)
+	return res
+}
+
+func (c *Checker) follow(ctx context.Context, method, rawURL string) Result {
+	var res Result
+	target := rawURL
+	for hop := 0; ; hop++ {
+		resp, err := c.do(ctx, method, target)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		res.StatusCode = resp.StatusCode
+
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+			// 416 answers the byte range of an empty resource that exists.
+			res.OK = resp.StatusCode >= 200 && resp.StatusCode < 300 ||
+				method == http.MethodGet && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable
+			if !res.OK {
+				res.Error = http.StatusText(resp.StatusCode)
+			}
+			return res
+		}
+
+		location, err := resp.Location()
+		if err != nil {
+			res.Error = "redirect without a valid Location"
+			return res
+		}
+		if location.Scheme != "http" && location.Scheme != "https" {
+			res.Error = fmt.Sprintf("redirect to unsupported scheme %q", location.Scheme)
+			return res
+		}
+		if hop == maxRedirects {
+			res.Error = "too many redirects"
+			return res
+		}
+		target = location.String()
+		res.Chain = append(res.Chain, target)
+	}
+}
+
+func (c *Checker) do(ctx context.Context, method, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q", req.URL.Scheme)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	release, err := c.hosts.Acquire(ctx, req.URL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, drainLimit))
+	resp.Body.Close()
+	return resp, nil
+}
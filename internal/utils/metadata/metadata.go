@@ -0,0 +1,213 @@
+// Package metadata fetches web pages and extracts the title, description,
+// preview image and favicon from their <head>.
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/OxytocinGroup/theca-v3/internal/utils/safehttp"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const userAgent = "Mozilla/5.0 (compatible; viabot/1.0; +https://via.oxytocingroup.com)"
+
+var ErrNotHTML = errors.New("metadata: response is not an HTML page")
+
+// Metadata describes a page. URLs are absolute; empty fields were not found.
+type Metadata struct {
+	Title       string
+	Description string
+	ImageURL    string
+	IconURL     string
+}
+
+// Fetcher downloads pages with a bounded body size and at most perHost
+// concurrent requests to the same host. It is safe for concurrent use.
+type Fetcher struct {
+	client   *http.Client
+	hosts    *safehttp.HostLimiter
+	maxBytes int64
+}
+
+func NewFetcher(client *http.Client, maxBytes int64, perHost int) *Fetcher {
+	return &Fetcher{
+		client:   client,
+		hosts:    safehttp.NewHostLimiter(perHost),
+		maxBytes: maxBytes,
+	}
+}
+
+// Fetch downloads rawURL and parses its metadata. Pages longer than the size
+// limit are parsed up to the limit, which normally covers the <head>.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Metadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("metadata: unsupported scheme %q", req.URL.Scheme)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	release, err := f.hosts.Acquire(ctx, req.URL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata: unexpected status %d", resp.StatusCode)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrNotHTML
+	}
+
+	return Parse(io.LimitReader(resp.Body, f.maxBytes), resp.Request.URL), nil
+}
+
+// Parse extracts metadata from an HTML document served at base. OpenGraph
+// values take precedence over Twitter card values, which take precedence
+// over <title> and the description meta tag. Without an icon link the
+// favicon defaults to /favicon.ico.
+func Parse(r io.Reader, base *url.URL) *Metadata {
+	var (
+		title, description, image = map[string]string{}, map[string]string{}, map[string]string{}
+		icon, touchIcon           string
+		inTitle                   bool
+	)
+
+	z := html.NewTokenizer(r)
+loop:
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			break loop
+		case html.TextToken:
+			if inTitle && title["html"] == "" {
+				title["html"] = string(z.Text())
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				break loop
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			tag := atom.Lookup(name)
+			attrs := map[string]string{}
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				attrs[string(key)] = string(val)
+			}
+
+			switch tag {
+			case atom.Title:
+				inTitle = true
+			case atom.Body:
+				break loop
+			case atom.Base:
+				if href, err := base.Parse(attrs["href"]); err == nil && attrs["href"] != "" {
+					base = href
+				}
+			case atom.Meta:
+				key := strings.ToLower(attrs["property"])
+				if key == "" {
+					key = strings.ToLower(attrs["name"])
+				}
+				content := attrs["content"]
+				switch key {
+				case "og:title":
+					setOnce(title, "og", content)
+				case "twitter:title":
+					setOnce(title, "twitter", content)
+				case "og:description":
+					setOnce(description, "og", content)
+				case "twitter:description":
+					setOnce(description, "twitter", content)
+				case "description":
+					setOnce(description, "html", content)
+				case "og:image", "og:image:url", "og:image:secure_url":
+					setOnce(image, "og", content)
+				case "twitter:image", "twitter:image:src":
+					setOnce(image, "twitter", content)
+				}
+			case atom.Link:
+				rels := strings.Fields(strings.ToLower(attrs["rel"]))
+				for _, rel := range rels {
+					switch {
+					case rel == "icon" && icon == "":
+						icon = attrs["href"]
+					case strings.HasPrefix(rel, "apple-touch-icon") && touchIcon == "":
+						touchIcon = attrs["href"]
+					}
+				}
+			}
+		}
+	}
+
+	m := &Metadata{
+		Title:       clean(first(title)),
+		Description: clean(first(description)),
+		ImageURL:    resolve(base, first(image)),
+		IconURL:     resolve(base, icon),
+	}
+	if m.IconURL == "" {
+		m.IconURL = resolve(base, touchIcon)
+	}
+	if m.IconURL == "" {
+		m.IconURL = resolve(base, "/favicon.ico")
+	}
+	return m
+}
+
+func setOnce(values map[string]string, source, value string) {
+	if _, ok := values[source]; !ok && strings.TrimSpace(value) != "" {
+		values[source] = value
+	}
+}
+
+func first(values map[string]string) string {
+	for _, source := range []string{"og", "twitter", "html"} {
+		if v := values[source]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// clean collapses whitespace and drops invalid UTF-8, which pages in legacy
+// encodings produce.
+func clean(s string) string {
+	return strings.Join(strings.Fields(strings.ToValidUTF8(s, "")), " ")
+}
+
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,132 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const page = `<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>
+		Plain   title
+	</title>
+	<meta name="description" content="Plain description">
+	<meta property="og:title" content="OpenGraph title">
+	<meta name="twitter:description" content="Twitter description">
+	<meta property="og:image" content="/images/cover.png">
+	<link rel="apple-touch-icon" href="/touch.png">
+	<link rel="shortcut icon" href="icons/favicon.png">
+</head>
+<body><title>Not the title</title></body>
+</html>`
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/docs/page", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/docs/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<head><title>Moved</title><link rel="icon" href="favicon.svg"></head>`))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<head><title>Bare</title></head>`))
+	})
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"title": "not a page"}`))
+	})
+	mux.HandleFunc("/long", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<head><!--" + strings.Repeat("x", 4096) + "--><title>Too late</title></head>"))
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetch(t *testing.T) {
+	srv := newTestServer(t)
+	f := NewFetcher(srv.Client(), 1024, 2)
+
+	tests := []struct {
+		path string
+		want Metadata
+	}{
+		{"/page", Metadata{
+			Title:       "OpenGraph title",
+			Description: "Twitter description",
+			ImageURL:    srv.URL + "/images/cover.png",
+			IconURL:     srv.URL + "/icons/favicon.png",
+		}},
+		// Relative URLs resolve against the page the redirect ended on.
+		{"/moved", Metadata{Title: "Moved", IconURL: srv.URL + "/docs/favicon.svg"}},
+		// Only the first kilobyte is read, which here ends before the title.
+		{"/long", Metadata{IconURL: srv.URL + "/favicon.ico"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := f.Fetch(context.Background(), srv.URL+tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Fatalf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	srv := newTestServer(t)
+	f := NewFetcher(srv.Client(), 1024, 2)
+
+	tests := []struct {
+		url     string
+		wantErr error
+	}{
+		{srv.URL + "/json", ErrNotHTML},
+		{srv.URL + "/missing", nil},
+		{"ftp://example.com/", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			_, err := f.Fetch(context.Background(), tt.url)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Pages served without a Content-Type are sniffed by net/http, and a missing
+// icon link falls back to /favicon.ico.
+func TestParseDefaults(t *testing.T) {
+	srv := newTestServer(t)
+	f := NewFetcher(srv.Client(), 1024, 2)
+
+	got, err := f.Fetch(context.Background(), srv.URL+"/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Metadata{Title: "Bare", IconURL: srv.URL + "/favicon.ico"}
+	if *got != want {
+		t.Fatalf("got %+v, want %+v", *got, want)
+	}
+}
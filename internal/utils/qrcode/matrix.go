@@ -0,0 +1,276 @@
+package qrcode
+
+// matrix is a symbol under construction. function marks the modules of
+// finder, timing, alignment, format and version patterns, which data and
+// masks leave alone.
+type matrix struct {
+	modules  []bool
+	function []bool
+	size     int
+	version  int
+}
+
+func newMatrix(version int) *matrix {
+	size := 17 + 4*version
+	return &matrix{
+		modules:  make([]bool, size*size),
+		function: make([]bool, size*size),
+		size:     size,
+		version:  version,
+	}
+}
+
+func (m *matrix) setFunction(x, y int, black bool) {
+	m.modules[y*m.size+x] = black
+	m.function[y*m.size+x] = true
+}
+
+func (m *matrix) drawFunctionPatterns() {
+	for i := 0; i < m.size; i++ {
+		m.setFunction(6, i, i%2 == 0)
+		m.setFunction(i, 6, i%2 == 0)
+	}
+
+	m.drawFinder(3, 3)
+	m.drawFinder(m.size-4, 3)
+	m.drawFinder(3, m.size-4)
+
+	pos := alignmentPositions(m.version)
+	last := len(pos) - 1
+	for i := range pos {
+		for j := range pos {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue // overlaps a finder pattern
+			}
+			m.drawAlignment(pos[i], pos[j])
+		}
+	}
+
+	// Reserve the format areas; the real bits depend on the mask.
+	m.drawFormatBits(0, 0)
+	m.drawVersion()
+}
+
+func (m *matrix) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || y < 0 || x >= m.size || y >= m.size {
+				continue
+			}
+			d := max(abs(dx), abs(dy))
+			m.setFunction(x, y, d != 2 && d != 4)
+		}
+	}
+}
+
+func (m *matrix) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			m.setFunction(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// alignmentPositions returns the row and column centres of the alignment
+// patterns of a version.
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	n := version/7 + 2
+	step := (version*8 + n*3 + 5) / (n*4 - 4) * 2
+	pos := make([]int, n)
+	pos[0] = 6
+	for i, p := n-1, 17+4*version-7; i >= 1; i, p = i-1, p-step {
+		pos[i] = p
+	}
+	return pos
+}
+
+func (m *matrix) drawFormatBits(level Level, mask int) {
+	data := level.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	for i := 0; i <= 5; i++ {
+		m.setFunction(8, i, bit(bits, i))
+	}
+	m.setFunction(8, 7, bit(bits, 6))
+	m.setFunction(8, 8, bit(bits, 7))
+	m.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		m.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		m.setFunction(m.size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		m.setFunction(8, m.size-15+i, bit(bits, i))
+	}
+	m.setFunction(8, m.size-8, true) // the dark module
+}
+
+func (m *matrix) drawVersion() {
+	if m.version < 7 {
+		return
+	}
+	rem := m.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := m.version<<12 | rem
+
+	for i := 0; i < 18; i++ {
+		a, b := m.size-11+i%3, i/3
+		m.setFunction(a, b, bit(bits, i))
+		m.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords places the codewords in the zigzag order of the standard:
+// two-module columns from the right, alternating upwards and downwards and
+// skipping the vertical timing pattern.
+func (m *matrix) drawCodewords(data []byte) {
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < m.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = m.size - 1 - vert
+				}
+				if !m.function[y*m.size+x] && i < len(data)*8 {
+					m.modules[y*m.size+x] = bit(int(data[i>>3]), 7-i&7)
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (m *matrix) applyMask(mask int) {
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !m.function[y*m.size+x] {
+				m.modules[y*m.size+x] = !m.modules[y*m.size+x]
+			}
+		}
+	}
+}
+
+// penalty scores the symbol with the four rules of the standard; the mask
+// with the lowest score is the easiest to scan.
+func (m *matrix) penalty() int {
+	at := func(x, y int) bool { return m.modules[y*m.size+x] }
+	score := 0
+
+	for _, horizontal := range []bool{true, false} {
+		for a := 0; a < m.size; a++ {
+			line := make([]bool, m.size)
+			for b := range line {
+				if horizontal {
+					line[b] = at(b, a)
+				} else {
+					line[b] = at(a, b)
+				}
+			}
+			score += linePenalty(line)
+		}
+	}
+
+	dark := 0
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if at(x, y) {
+				dark++
+			}
+			if x+1 < m.size && y+1 < m.size {
+				c := at(x, y)
+				if at(x+1, y) == c && at(x, y+1) == c && at(x+1, y+1) == c {
+					score += 3
+				}
+			}
+		}
+	}
+
+	total := m.size * m.size
+	score += abs(dark*100/total-50) / 5 * 10
+	return score
+}
+
+var (
+	finderLike         = []bool{true, false, true, true, true, false, true, false, false, false, false}
+	finderLikeReversed = []bool{false, false, false, false, true, false, true, true, true, false, true}
+)
+
+// linePenalty applies rules 1 and 3 to a row or column: runs of five or more
+// modules of one colour and patterns that look like a finder.
+func linePenalty(line []bool) int {
+	score := 0
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			score += 3 + run - 5
+		}
+		run = 1
+	}
+
+	for i := 0; i+len(finderLike) <= len(line); i++ {
+		if matchAt(line, i, finderLike) || matchAt(line, i, finderLikeReversed) {
+			score += 40
+		}
+	}
+	return score
+}
+
+func matchAt(line []bool, i int, pattern []bool) bool {
+	for j, p := range pattern {
+		if line[i+j] != p {
+			return false
+		}
+	}
+	return true
+}
+
+func bit(x, i int) bool {
+	return (x>>i)&1 != 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
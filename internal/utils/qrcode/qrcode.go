@@ -0,0 +1,145 @@
+// Package qrcode encodes data as QR Code symbols (ISO/IEC 18004) in byte
+// mode and renders them as PNG or SVG images.
+package qrcode
+
+import (
+	"errors"
+	"strings"
+)
+
+// Level is the error correction level of a symbol. Higher levels recover
+// more damage, such as a logo drawn over the centre, at the cost of a larger
+// symbol.
+type Level int
+
+const (
+	LevelL Level = iota // recovers about 7% of codewords
+	LevelM              // about 15%
+	LevelQ              // about 25%
+	LevelH              // about 30%
+)
+
+const (
+	minVersion = 1
+	maxVersion = 40
+)
+
+var ErrTooLong = errors.New("qrcode: data too long")
+
+// ParseLevel parses a level name such as "M"; the empty string means LevelM.
+func ParseLevel(s string) (Level, bool) {
+	switch strings.ToUpper(s) {
+	case "L":
+		return LevelL, true
+	case "", "M":
+		return LevelM, true
+	case "Q":
+		return LevelQ, true
+	case "H":
+		return LevelH, true
+	}
+	return 0, false
+}
+
+// formatBits are the two bits that identify a level in the format
+// information, which does not follow the order of the levels.
+func (l Level) formatBits() int {
+	return [...]int{1, 0, 3, 2}[l]
+}
+
+// Code is an encoded QR Code symbol.
+type Code struct {
+	modules []bool
+	Size    int
+	Version int
+	Level   Level
+}
+
+// Black reports whether the module at column x and row y is dark. Modules
+// outside the symbol are light.
+func (c *Code) Black(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y*c.Size+x]
+}
+
+// Encode encodes data in the smallest version that fits at the given level.
+func Encode(data []byte, level Level) (*Code, error) {
+	version := minVersion
+	for ; version <= maxVersion; version++ {
+		if 4+charCountBits(version)+8*len(data) <= 8*numDataCodewords(version, level) {
+			break
+		}
+	}
+	if version > maxVersion {
+		return nil, ErrTooLong
+	}
+
+	codewords := addErrorCorrection(encodeData(data, version, level), version, level)
+
+	m := newMatrix(version)
+	m.drawFunctionPatterns()
+	m.drawCodewords(codewords)
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		m.applyMask(mask)
+		m.drawFormatBits(level, mask)
+		if p := m.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		m.applyMask(mask) // masks are their own inverse
+	}
+	m.applyMask(best)
+	m.drawFormatBits(level, best)
+
+	return &Code{modules: m.modules, Size: m.size, Version: version, Level: level}, nil
+}
+
+// encodeData builds the data codewords: byte mode indicator, character
+// count, the data itself, terminator and padding.
+func encodeData(data []byte, version int, level Level) []byte {
+	capacity := 8 * numDataCodewords(version, level)
+
+	var bb bitBuffer
+	bb.append(0b0100, 4)
+	bb.append(len(data), charCountBits(version))
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+	bb.append(0, min(4, capacity-bb.len()))
+	bb.append(0, (8-bb.len()%8)%8)
+	for pad := 0xEC; bb.len() < capacity; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+	return bb.bytes()
+}
+
+func charCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+type bitBuffer struct {
+	bits []bool
+}
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		b.bits = append(b.bits, (value>>i)&1 == 1)
+	}
+}
+
+func (b *bitBuffer) len() int {
+	return len(b.bits)
+}
+
+func (b *bitBuffer) bytes() []byte {
+	out := make([]byte, len(b.bits)/8)
+	for i, bit := range b.bits {
+		if bit {
+			out[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return out
+}
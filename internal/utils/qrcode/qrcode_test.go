@@ -0,0 +1,172 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// The symbols below were cross-checked against an independent encoder with
+// the same mask; '#' is a dark module.
+
+// helloM is "HELLO" at LevelM, version 1 with mask 4.
+const helloM = `
+#######.##.#..#######
+#.....#..##.#.#.....#
+#.###.#..####.#.###.#
+#.###.#.#..#..#.###.#
+#.###.#.#...#.#.###.#
+#.....#.#.##..#.....#
+#######.#.#.#.#######
+........#####........
+#...#.######.#####..#
+...###..#.###..#.####
+#.##..#.#.##..###..#.
+###..#...#...##.#....
+..#.###..#..###...##.
+........###.###..#.##
+#######.##..##...#.#.
+#.....#....##..#...#.
+#.###.#.#..#..###.#.#
+#.###.#....##....#.##
+#.###.#..###..####...
+#.....#..#...##......
+#######.#...#####.#.#
+`
+
+// digitsM is "0123456789" eleven times at LevelM, version 7 with mask 2, the
+// first version carrying version information.
+const digitsM = `
+#######....###.#.#.....#...#..####..#.#######
+#.....#...#.#####....#..####.#...#.#..#.....#
+#.###.#.###.#.#..##.##.#....######.#..#.###.#
+#.###.#.#..#.###..###.#.##.#..#..#.##.#.###.#
+#.###.#.#..#.#.#.#..#####.....##..###.#.###.#
+#.....#.##..##.###..#...##.###.#......#.....#
+#######.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#######
+........####.##....##...#.#.#######.#........
+#.#####.....#.#.##.#######.#.##..#.#..#####..
+#.#.....##.##.#...##...##..#..#..#..#..#..###
+.#.##.#...#....##..#....######.#..#####..##..
+..#.##..#.#...###.#..#.##..#.######..#..#.#..
+##.#..#....###.#..##..#.#...#......#.#.#.#...
+#..#.#.#.#.##.##.#....##...##.####..#..##.###
+###...#...#######..####.###.##....#.###......
+..#.#..##.#..###.##...#.#...######...#..#.#.#
+#..#######..#.#...#..#.###.#..#..#.#..##.#...
+##..##..##..##.#.#..#..#......#.##..#.....###
+.#.######.#.#..#..####..######....##.##..#...
+.#####.#..#.##..#.#..#.#.##.######.###..#.##.
+#.#######....####.#######.##.##....#######...
+###.#...#.#.##..##.##...####..#..#.##...#.###
+###.#.#.####.###..###.#.#..###.#..#.#.#.###..
+##..#...#.#.###.###.#...#...#######.#...#.#..
+.##.######.....#..#######..#.......#######...
+#...##.#..###.#.####..#.#...#.####..####..###
+#..##.#..#..#.#..#.....#.##..#....##...#.....
+#.##.....#..#.#.#####...#..#.#####.####...#.#
+#.#.####.#.#......#..#...#.#.#...#..##..##.##
+##..##..#.##.###..#.#.#.#.....#.##..##.#..###
+.#.####.##..##.....#....######....##....##...
+.#..##.##..##..#.#.#####....######.#####..##.
+##.#.##.#....#.##..###....##.##......#..##...
+.##.##.##.###..#.#.#..##...#..##.#.#..##.####
+....#.#.####.###....##.#.#####....#.......#..
+.####....#######.####...#...######.####...#..
+#..##.#..##.....##..######.#.....##.######...
+........#...#.##.####...#...#.####..#...#.###
+#######...##......###.#.##...#....###.#.#....
+#.....#.##..####....#...#...######.##...#.##.
+#.###.#.##....#.#...######.#.#...#..######..#
+#.###.#.##..###...#.##.#...##.#.##.#.#..#.#.#
+#.###.#.#####.##.###....###..#....#.####.#.#.
+#.....#..#.....##..##..#....#..###..#..#..#..
+#######.#.##.#.#........#.##..#......##..#.#.
+`
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		level   Level
+		version int
+		want    string
+	}{
+		{"version 1", "HELLO", LevelM, 1, helloM},
+		{"version 7", strings.Repeat("0123456789", 11), LevelM, 7, digitsM},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Encode([]byte(tt.data), tt.level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Version != tt.version {
+				t.Fatalf("got version %d, want %d", c.Version, tt.version)
+			}
+
+			want := strings.Fields(tt.want)
+			if c.Size != len(want) {
+				t.Fatalf("got size %d, want %d", c.Size, len(want))
+			}
+			for y, row := range want {
+				for x := range row {
+					if c.Black(x, y) != (row[x] == '#') {
+						t.Errorf("module (%d, %d) is dark: %v, want %v", x, y, c.Black(x, y), row[x] == '#')
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	// Version 40 holds 2953 bytes at LevelL and 1273 at LevelH.
+	if _, err := Encode(make([]byte, 2953), LevelL); err != nil {
+		t.Fatalf("2953 bytes at LevelL: %v", err)
+	}
+	if _, err := Encode(make([]byte, 1274), LevelH); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("1274 bytes at LevelH: got error %v, want %v", err, ErrTooLong)
+	}
+}
+
+func TestRender(t *testing.T) {
+	c, err := Encode([]byte("HELLO"), LevelM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	style := Style{Size: 100, Margin: DefaultMargin}
+
+	var buf bytes.Buffer
+	if err := c.PNG(&buf, style); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 29 modules with the margin fit three times into 100 pixels, centred.
+	if b := img.Bounds(); b.Dx() != 100 || b.Dy() != 100 {
+		t.Fatalf("got a %dx%d image, want 100x100", b.Dx(), b.Dy())
+	}
+	offset := (100-3*29)/2 + 3*DefaultMargin
+	if r, _, _, _ := img.At(offset, offset).RGBA(); r != 0 {
+		t.Error("the top left finder module is not dark")
+	}
+	if r, _, _, _ := img.At(offset-1, offset-1).RGBA(); r == 0 {
+		t.Error("the margin is not light")
+	}
+
+	buf.Reset()
+	if err := c.SVG(&buf, style); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	for _, want := range []string{`width="100"`, `viewBox="0 0 29 29"`, `fill="#ffffff"`, `<path d="M4 4h7v1h-7z`} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG lacks %s:\n%s", want, svg)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// DefaultMargin is the quiet zone the standard requires around a symbol, in
+// modules.
+const DefaultMargin = 4
+
+// Style controls how a symbol is rendered.
+type Style struct {
+	Foreground color.Color
+	Background color.Color
+	// Logo is drawn over the centre of PNG images, on a background-coloured
+	// pad covering at most a fifth of the symbol width. Pair it with LevelH
+	// or LevelQ so the covered modules can be recovered.
+	Logo image.Image
+	// Size is the width and height of the image in pixels, or in user units
+	// for SVG. PNG images are never smaller than one pixel per module.
+	Size   int
+	Margin int
+}
+
+func (s Style) colors() (color.Color, color.Color) {
+	fg, bg := s.Foreground, s.Background
+	if fg == nil {
+		fg = color.Black
+	}
+	if bg == nil {
+		bg = color.White
+	}
+	return fg, bg
+}
+
+// Image renders the symbol as an image of s.Size pixels, with modules scaled
+// by a whole number of pixels and centred.
+func (c *Code) Image(s Style) image.Image {
+	fg, bg := s.colors()
+	total := c.Size + 2*s.Margin
+	size := max(s.Size, total)
+	scale := size / total
+	offset := (size-scale*total)/2 + s.Margin*scale
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	dark := image.NewUniform(fg)
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Black(x, y) {
+				r := image.Rect(offset+x*scale, offset+y*scale, offset+(x+1)*scale, offset+(y+1)*scale)
+				draw.Draw(img, r, dark, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	if s.Logo != nil {
+		symbol := c.Size * scale
+		drawLogo(img, s.Logo, offset+symbol/2, symbol/5, bg)
+	}
+	return img
+}
+
+// drawLogo scales logo to fit a box of the given side centred on centre and
+// draws it over a padded background.
+func drawLogo(dst *image.RGBA, logo image.Image, centre, side int, bg color.Color) {
+	b := logo.Bounds()
+	if side < 4 || b.Dx() == 0 || b.Dy() == 0 {
+		return
+	}
+	pad := max(side/10, 1)
+	inner := side - 2*pad
+	w, h := inner, inner
+	if b.Dx() > b.Dy() {
+		h = max(inner*b.Dy()/b.Dx(), 1)
+	} else {
+		w = max(inner*b.Dx()/b.Dy(), 1)
+	}
+
+	box := image.Rect(centre-w/2-pad, centre-h/2-pad, centre-w/2+w+pad, centre-h/2+h+pad)
+	draw.Draw(dst, box, image.NewUniform(bg), image.Point{}, draw.Src)
+	target := image.Rect(centre-w/2, centre-h/2, centre-w/2+w, centre-h/2+h)
+	draw.Draw(dst, target, scaleImage(logo, w, h), image.Point{}, draw.Over)
+}
+
+// scaleImage resizes src to w×h by averaging the source pixels that fall in
+// each destination pixel, which is good enough for downscaling a logo.
+func scaleImage(src image.Image, w, h int) image.Image {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(b.Min.Y+(y+1)*b.Dy()/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(b.Min.X+(x+1)*b.Dx()/w, x0+1)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					bl += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			dst.Set(x, y, color.NRGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// PNG writes the symbol as a PNG image.
+func (c *Code) PNG(w io.Writer, s Style) error {
+	return png.Encode(w, c.Image(s))
+}
+
+// SVG writes the symbol as an SVG document with one path for the dark
+// modules. The logo of the style is not supported.
+func (c *Code) SVG(w io.Writer, s Style) error {
+	fg, bg := s.colors()
+	total := c.Size + 2*s.Margin
+	size := max(s.Size, total)
+
+	var path strings.Builder
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; {
+			if !c.Black(x, y) {
+				x++
+				continue
+			}
+			run := 1
+			for c.Black(x+run, y) {
+				run++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", x+s.Margin, y+s.Margin, run, run)
+			x += run
+		}
+	}
+
+	_, err := fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">
+<rect width="%d" height="%d"%s/>
+<path d="%s"%s/>
+</svg>
+`, size, size, total, total, total, total, svgFill(bg), path.String(), svgFill(fg))
+	return err
+}
+
+func svgFill(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	fill := fmt.Sprintf(` fill="#%02x%02x%02x"`, n.R, n.G, n.B)
+	if n.A != 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%.3g"`, float64(n.A)/0xff)
+	}
+	return fill
+}
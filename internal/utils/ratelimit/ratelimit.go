@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// FailureLimiter blocks a key after too many failed attempts within a window.
+// Only failures are counted, so legitimate traffic is never throttled.
+type FailureLimiter struct {
+	entries   map[string]*entry
+	lastPrune time.Time
+	mu        sync.Mutex
+	window    time.Duration
+	max       int
+}
+
+type entry struct {
+	resetAt  time.Time
+	failures int
+}
+
+func NewFailureLimiter(maxFailures int, window time.Duration) *FailureLimiter {
+	return &FailureLimiter{
+		entries:   make(map[string]*entry),
+		lastPrune: time.Now(),
+		window:    window,
+		max:       maxFailures,
+	}
+}
+
+// Blocked reports whether key has exhausted its failures for the current window.
+func (l *FailureLimiter) Blocked(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[key]
+	if !ok {
+		return false
+	}
+	if time.Now().After(e.resetAt) {
+		delete(l.entries, key)
+		return false
+	}
+	return e.failures >= l.max
+}
+
+// RecordFailure counts a failed attempt for key.
+func (l *FailureLimiter) RecordFailure(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.prune(now)
+
+	e, ok := l.entries[key]
+	if !ok || now.After(e.resetAt) {
+		e = &entry{resetAt: now.Add(l.window)}
+		l.entries[key] = e
+	}
+	e.failures++
+}
+
+// prune drops expired entries at most once per window so the map cannot
+// grow without bound under a spray of distinct keys.
+func (l *FailureLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < l.window {
+		return
+	}
+	for key, e := range l.entries {
+		if now.After(e.resetAt) {
+			delete(l.entries, key)
+		}
+	}
+	l.lastPrune = now
+}
@@ -0,0 +1,230 @@
+// Package referrer turns the Referer header of a click, or the utm_source it
+// was tagged with, into a normalised source host and a class such as search
+// or social.
+package referrer
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	ClassDirect   = "direct"
+	ClassInternal = "internal"
+	ClassSearch   = "search"
+	ClassSocial   = "social"
+	ClassEmail    = "email"
+	ClassReferral = "referral"
+)
+
+// Classes lists every class a source can be put in.
+var Classes = []string{ClassDirect, ClassInternal, ClassSearch, ClassSocial, ClassEmail, ClassReferral}
+
+// Source is where a click came from. Host is empty for direct clicks.
+type Source struct {
+	Host  string
+	Class string
+}
+
+type known struct {
+	class string
+	// host is the canonical host all aliases are reported as.
+	host string
+	// aliases are further domains, Android app packages and, without a
+	// dot, brand names matched as a label followed by a country suffix
+	// (google.co.uk) or used as utm_source.
+	aliases []string
+}
+
+var builtin = []known{
+	{ClassSearch, "google.com", []string{"google", "com.google.android.googlequicksearchbox"}},
+	{ClassSearch, "bing.com", []string{"bing"}},
+	{ClassSearch, "yahoo.com", []string{"yahoo", "search.yahoo.com"}},
+	{ClassSearch, "duckduckgo.com", []string{"duckduckgo", "ddg.gg"}},
+	{ClassSearch, "yandex.ru", []string{"yandex", "ya.ru"}},
+	{ClassSearch, "baidu.com", []string{"baidu"}},
+	{ClassSearch, "ecosia.org", []string{"ecosia"}},
+	{ClassSearch, "search.brave.com", nil},
+	{ClassSearch, "startpage.com", []string{"startpage"}},
+	{ClassSearch, "qwant.com", []string{"qwant"}},
+
+	{ClassSocial, "facebook.com", []string{"facebook", "fb", "fb.com", "fb.me", "com.facebook.katana", "com.facebook.orca"}},
+	{ClassSocial, "instagram.com", []string{"instagram", "ig", "com.instagram.android"}},
+	{ClassSocial, "x.com", []string{"twitter", "twitter.com", "t.co", "com.twitter.android"}},
+	{ClassSocial, "linkedin.com", []string{"linkedin", "lnkd.in", "com.linkedin.android"}},
+	{ClassSocial, "reddit.com", []string{"reddit", "redd.it", "com.reddit.frontpage"}},
+	{ClassSocial, "youtube.com", []string{"youtube", "youtu.be", "com.google.android.youtube"}},
+	{ClassSocial, "tiktok.com", []string{"tiktok"}},
+	{ClassSocial, "pinterest.com", []string{"pinterest", "pin.it"}},
+	{ClassSocial, "vk.com", []string{"vk", "vkontakte", "com.vkontakte.android"}},
+	{ClassSocial, "ok.ru", []string{"odnoklassniki"}},
+	{ClassSocial, "t.me", []string{"telegram", "telegram.org", "web.telegram.org", "org.telegram.messenger"}},
+	{ClassSocial, "whatsapp.com", []string{"whatsapp", "wa.me", "com.whatsapp"}},
+	{ClassSocial, "threads.net", []string{"threads"}},
+	{ClassSocial, "bsky.app", []string{"bluesky"}},
+	{ClassSocial, "mastodon.social", []string{"mastodon"}},
+	{ClassSocial, "news.ycombinator.com", []string{"hackernews"}},
+
+	{ClassEmail, "mail.google.com", []string{"gmail", "com.google.android.gm"}},
+	{ClassEmail, "outlook.live.com", []string{"outlook", "outlook.office.com", "outlook.office365.com", "com.microsoft.office.outlook"}},
+	{ClassEmail, "mail.yahoo.com", nil},
+	{ClassEmail, "mail.yandex.ru", nil},
+	{ClassEmail, "mail.ru", []string{"e.mail.ru"}},
+	{ClassEmail, "mail.proton.me", []string{"protonmail", "mail.protonmail.com"}},
+}
+
+// mediumClasses maps utm_medium values to the class they imply.
+var mediumClasses = map[string]string{
+	"email":          ClassEmail,
+	"e-mail":         ClassEmail,
+	"newsletter":     ClassEmail,
+	"social":         ClassSocial,
+	"social-media":   ClassSocial,
+	"social-network": ClassSocial,
+	"sm":             ClassSocial,
+	"organic":        ClassSearch,
+	"cpc":            ClassSearch,
+	"ppc":            ClassSearch,
+	"paid-search":    ClassSearch,
+}
+
+// Classifier classifies sources by the built-in table and any entries
+// added to it. It is safe for concurrent use once set up.
+type Classifier struct {
+	domains map[string]Source
+	names   map[string]Source
+}
+
+func NewClassifier() *Classifier {
+	c := &Classifier{
+		domains: make(map[string]Source),
+		names:   make(map[string]Source),
+	}
+	for _, k := range builtin {
+		src := Source{Host: k.host, Class: k.class}
+		c.domains[k.host] = src
+		for _, alias := range k.aliases {
+			c.add(alias, src)
+		}
+	}
+	return c
+}
+
+// Add classifies a domain, including its subdomains, or a brand name. Later
+// entries win over earlier and built-in ones.
+func (c *Classifier) Add(host, class string) error {
+	if !slices.Contains(Classes, class) {
+		return fmt.Errorf("referrer: unknown class %q", class)
+	}
+	host = normalizeHost(host)
+	if host == "" {
+		return fmt.Errorf("referrer: empty host")
+	}
+	c.add(host, Source{Host: host, Class: class})
+	return nil
+}
+
+func (c *Classifier) add(alias string, src Source) {
+	if strings.Contains(alias, ".") {
+		c.domains[alias] = src
+	} else {
+		c.names[alias] = src
+	}
+}
+
+// Referrer classifies a Referer header. Referrers from any of the internal
+// hosts, such as the short link domain itself, are internal.
+func (c *Classifier) Referrer(referer string, internal ...string) Source {
+	referer = strings.TrimSpace(referer)
+	if referer == "" {
+		return Source{Class: ClassDirect}
+	}
+	u, err := url.Parse(referer)
+	if err != nil || u.Host == "" {
+		return Source{Class: ClassDirect}
+	}
+
+	host := normalizeHost(u.Host)
+	for _, h := range internal {
+		if h != "" && normalizeHost(h) == host {
+			return Source{Host: host, Class: ClassInternal}
+		}
+	}
+	if src, ok := c.lookup(host); ok {
+		return src
+	}
+	return Source{Host: host, Class: ClassReferral}
+}
+
+// Campaign classifies the utm_source and utm_medium a click was tagged with.
+// A known medium, such as email, decides the class over the source.
+func (c *Classifier) Campaign(source, medium string) Source {
+	source = strings.ToLower(strings.TrimSpace(source))
+	src, ok := c.lookup(normalizeHost(source))
+	if !ok {
+		src = Source{Host: source, Class: ClassReferral}
+	}
+	if class, ok := mediumClasses[strings.ToLower(strings.TrimSpace(medium))]; ok {
+		src.Class = class
+	}
+	return src
+}
+
+func (c *Classifier) lookup(host string) (Source, bool) {
+	if src, ok := c.names[host]; ok {
+		return src, true
+	}
+
+	// The host itself or the closest parent domain in the table.
+	for h := host; h != ""; {
+		if src, ok := c.domains[h]; ok {
+			return src, true
+		}
+		_, parent, found := strings.Cut(h, ".")
+		if !found {
+			break
+		}
+		h = parent
+	}
+
+	// A brand followed by nothing but a country suffix, as in google.de or
+	// yandex.com.tr.
+	labels := strings.Split(host, ".")
+	for i, label := range labels[:len(labels)-1] {
+		src, ok := c.names[label]
+		if ok && isCountrySuffix(labels[i+1:]) {
+			return src, true
+		}
+	}
+	return Source{}, false
+}
+
+func isCountrySuffix(labels []string) bool {
+	if len(labels) > 2 {
+		return false
+	}
+	for _, l := range labels {
+		if len(l) > 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeHost lowercases a host and strips the port and the www. or m.
+// prefix.
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	for _, prefix := range []string{"www.", "m.", "mobile."} {
+		if rest, ok := strings.CutPrefix(host, prefix); ok && strings.Contains(rest, ".") {
+			return rest
+		}
+	}
+	return host
+}
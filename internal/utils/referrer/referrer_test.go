@@ -0,0 +1,99 @@
+package referrer
+
+import "testing"
+
+func TestReferrer(t *testing.T) {
+	c := NewClassifier()
+
+	tests := []struct {
+		referer string
+		want    Source
+	}{
+		{"", Source{Class: ClassDirect}},
+		{"   ", Source{Class: ClassDirect}},
+		{"not a url", Source{Class: ClassDirect}},
+		{"https://via.example.com/abc+", Source{Host: "via.example.com", Class: ClassInternal}},
+		{"https://VIA.example.com:443/", Source{Host: "via.example.com", Class: ClassInternal}},
+		{"https://www.google.com/search?q=via", Source{Host: "google.com", Class: ClassSearch}},
+		{"https://www.google.co.uk/", Source{Host: "google.com", Class: ClassSearch}},
+		{"https://yandex.com.tr/", Source{Host: "yandex.ru", Class: ClassSearch}},
+		{"https://search.yahoo.com/", Source{Host: "yahoo.com", Class: ClassSearch}},
+		{"https://mail.yahoo.com/d/folders/1", Source{Host: "mail.yahoo.com", Class: ClassEmail}},
+		{"https://l.facebook.com/l.php?u=x", Source{Host: "facebook.com", Class: ClassSocial}},
+		{"https://m.facebook.com/", Source{Host: "facebook.com", Class: ClassSocial}},
+		{"https://t.co/abc", Source{Host: "x.com", Class: ClassSocial}},
+		{"android-app://com.google.android.gm/", Source{Host: "mail.google.com", Class: ClassEmail}},
+		{"android-app://org.telegram.messenger", Source{Host: "t.me", Class: ClassSocial}},
+		{"https://www.blog.example.org/post", Source{Host: "blog.example.org", Class: ClassReferral}},
+		{"https://google.evil.com/", Source{Host: "google.evil.com", Class: ClassReferral}},
+		{"https://m.com/", Source{Host: "m.com", Class: ClassReferral}},
+	}
+	for _, tt := range tests {
+		if got := c.Referrer(tt.referer, "via.example.com", ""); got != tt.want {
+			t.Errorf("Referrer(%q) = %+v, want %+v", tt.referer, got, tt.want)
+		}
+	}
+}
+
+func TestCampaign(t *testing.T) {
+	c := NewClassifier()
+
+	tests := []struct {
+		source, medium string
+		want           Source
+	}{
+		{"google", "", Source{Host: "google.com", Class: ClassSearch}},
+		{"Facebook", "social", Source{Host: "facebook.com", Class: ClassSocial}},
+		{"facebook", "cpc", Source{Host: "facebook.com", Class: ClassSearch}},
+		{"newsletter", "Email", Source{Host: "newsletter", Class: ClassEmail}},
+		{"partner-site", "", Source{Host: "partner-site", Class: ClassReferral}},
+		{"www.linkedin.com", "", Source{Host: "linkedin.com", Class: ClassSocial}},
+		{"spring_sale", "banner", Source{Host: "spring_sale", Class: ClassReferral}},
+	}
+	for _, tt := range tests {
+		if got := c.Campaign(tt.source, tt.medium); got != tt.want {
+			t.Errorf("Campaign(%q, %q) = %+v, want %+v", tt.source, tt.medium, got, tt.want)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	c := NewClassifier()
+
+	if err := c.Add("intranet.example.com", ClassInternal); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add("news.ycombinator.com", ClassReferral); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add("newsletter", ClassEmail); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		referer string
+		want    Source
+	}{
+		{"https://docs.intranet.example.com/", Source{Host: "intranet.example.com", Class: ClassInternal}},
+		{"https://news.ycombinator.com/item?id=1", Source{Host: "news.ycombinator.com", Class: ClassReferral}},
+	}
+	for _, tt := range tests {
+		if got := c.Referrer(tt.referer); got != tt.want {
+			t.Errorf("Referrer(%q) = %+v, want %+v", tt.referer, got, tt.want)
+		}
+	}
+	if got := c.Campaign("newsletter", ""); got.Class != ClassEmail {
+		t.Errorf("added brand name classified as %s", got.Class)
+	}
+
+	// Classifiers do not share what is added to them.
+	if got := NewClassifier().Referrer("https://news.ycombinator.com/"); got.Class != ClassSocial {
+		t.Errorf("a new classifier sees an added entry: %+v", got)
+	}
+
+	for _, bad := range [][2]string{{"example.com", "paid"}, {" ", ClassSocial}} {
+		if err := c.Add(bad[0], bad[1]); err == nil {
+			t.Errorf("Add(%q, %q) succeeded", bad[0], bad[1])
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package safehttp
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// HostLimiter caps concurrent requests per host, so background jobs do not
+// hammer a site many links point to. Slots are dropped once no request uses
+// them, so the map does not grow with every host ever seen.
+type HostLimiter struct {
+	slots map[string]*hostSlot
+	mu    sync.Mutex
+	limit int
+}
+
+type hostSlot struct {
+	sem   chan struct{}
+	users int
+}
+
+func NewHostLimiter(limit int) *HostLimiter {
+	return &HostLimiter{slots: make(map[string]*hostSlot), limit: max(limit, 1)}
+}
+
+// Acquire waits for a free slot for host and returns the function releasing
+// it.
+func (l *HostLimiter) Acquire(ctx context.Context, host string) (func(), error) {
+	host = strings.ToLower(host)
+
+	l.mu.Lock()
+	slot, ok := l.slots[host]
+	if !ok {
+		slot = &hostSlot{sem: make(chan struct{}, l.limit)}
+		l.slots[host] = slot
+	}
+	slot.users++
+	l.mu.Unlock()
+
+	done := func() {
+		l.mu.Lock()
+		slot.users--
+		if slot.users == 0 {
+			delete(l.slots, host)
+		}
+		l.mu.Unlock()
+	}
+
+	select {
+	case slot.sem <- struct{}{}:
+		return func() {
+			<-slot.sem
+			done()
+		}, nil
+	case <-ctx.Done():
+		done()
+		return nil, ctx.Err()
+	}
+}
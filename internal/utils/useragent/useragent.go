@@ -0,0 +1,218 @@
+// Package useragent classifies User-Agent headers into a browser, operating
+// system and device class. Classification is driven by an ordered list of
+// rules; the rules built into the binary can be replaced by an updated file
+// without a rebuild.
+package useragent
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	DeviceUnknown = "unknown"
+)
+
+const (
+	OSAndroid  = "android"
+	OSIOS      = "ios"
+	OSWindows  = "windows"
+	OSMacOS    = "macos"
+	OSLinux    = "linux"
+	OSChromeOS = "chromeos"
+	OSOther    = "other"
+)
+
+// BrowserOther is the browser of agents no rule recognises.
+const BrowserOther = "Other"
+
+// maxVersion bounds the versions rules extract.
+const maxVersion = 32
+
+var (
+	Devices = []string{DeviceDesktop, DeviceMobile, DeviceTablet, DeviceBot, DeviceUnknown}
+	OSes    = []string{OSAndroid, OSIOS, OSWindows, OSMacOS, OSLinux, OSChromeOS, OSOther}
+)
+
+// Agent is a classified User-Agent. For bots, Browser is the bot family.
+type Agent struct {
+	Device         string
+	OS             string
+	OSVersion      string
+	Browser        string
+	BrowserVersion string
+}
+
+//go:embed rules.json
+var builtinRules []byte
+
+// Rules is the format of a rules file. Each list is tried in order and the
+// first rule whose case-insensitive regex matches wins. Versions are
+// expanded from Version, where $1 refers to the first capture group, or
+// else are the first non-empty capture group. An OS rule may name the
+// device its agents are on when no device rule matches.
+type Rules struct {
+	Bots     []Rule `json:"bots"`
+	Browsers []Rule `json:"browsers"`
+	OS       []Rule `json:"os"`
+	Devices  []Rule `json:"devices"`
+}
+
+type Rule struct {
+	Regex   string `json:"regex"`
+	Family  string `json:"family"`
+	Version string `json:"version"`
+	Device  string `json:"device"`
+}
+
+type rule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+// Parser classifies User-Agent headers by a set of rules. It is safe for
+// concurrent use.
+type Parser struct {
+	bots     []rule
+	browsers []rule
+	os       []rule
+	devices  []rule
+}
+
+// NewParser compiles a rules file.
+func NewParser(data []byte) (*Parser, error) {
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("useragent: invalid rules: %w", err)
+	}
+
+	var p Parser
+	var err error
+	if p.bots, err = compile("bots", rules.Bots); err != nil {
+		return nil, err
+	}
+	if p.browsers, err = compile("browsers", rules.Browsers); err != nil {
+		return nil, err
+	}
+	if p.os, err = compile("os", rules.OS); err != nil {
+		return nil, err
+	}
+	if p.devices, err = compile("devices", rules.Devices); err != nil {
+		return nil, err
+	}
+	for _, r := range slices.Concat(p.os, p.devices) {
+		if r.Device != "" && !slices.Contains(Devices, r.Device) {
+			return nil, fmt.Errorf("useragent: unknown device %q in rule %q", r.Device, r.Regex)
+		}
+	}
+	return &p, nil
+}
+
+// Load reads a rules file from disk.
+func Load(path string) (*Parser, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewParser(data)
+}
+
+var defaultParser = sync.OnceValue(func() *Parser {
+	p, err := NewParser(builtinRules)
+	if err != nil {
+		panic(err)
+	}
+	return p
+})
+
+// Default returns the parser for the rules built into the binary.
+func Default() *Parser {
+	return defaultParser()
+}
+
+// Parse classifies a User-Agent header. An empty header is treated as a bot,
+// since browsers always send one. Agents matching no device rule are on the
+// device of their OS rule, or else on an unknown one.
+func (p *Parser) Parse(ua string) Agent {
+	agent := Agent{Device: DeviceUnknown, OS: OSOther, Browser: BrowserOther}
+
+	if r, version, ok := match(p.os, ua); ok {
+		agent.OS, agent.OSVersion = r.Family, version
+		if r.Device != "" {
+			agent.Device = r.Device
+		}
+	}
+
+	if strings.TrimSpace(ua) == "" {
+		agent.Device = DeviceBot
+		return agent
+	}
+	if r, version, ok := match(p.bots, ua); ok {
+		agent.Device = DeviceBot
+		agent.Browser, agent.BrowserVersion = r.Family, version
+		return agent
+	}
+
+	if r, version, ok := match(p.browsers, ua); ok {
+		agent.Browser, agent.BrowserVersion = r.Family, version
+	}
+	if r, _, ok := match(p.devices, ua); ok {
+		agent.Device = r.Device
+	}
+	return agent
+}
+
+func compile(list string, rules []Rule) ([]rule, error) {
+	compiled := make([]rule, 0, len(rules))
+	for _, r := range rules {
+		if r.Regex == "" || (list != "devices" && r.Family == "") || (list == "devices" && r.Device == "") {
+			return nil, fmt.Errorf("useragent: incomplete rule %q in %s", r.Regex, list)
+		}
+		re, err := regexp.Compile("(?i)" + r.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("useragent: invalid regex in %s: %w", list, err)
+		}
+		compiled = append(compiled, rule{Rule: r, re: re})
+	}
+	return compiled, nil
+}
+
+func match(rules []rule, ua string) (rule, string, bool) {
+	for _, r := range rules {
+		m := r.re.FindStringSubmatchIndex(ua)
+		if m == nil {
+			continue
+		}
+		return r, version(r, ua, m), true
+	}
+	return rule{}, "", false
+}
+
+func version(r rule, ua string, m []int) string {
+	var v string
+	if r.Version != "" {
+		v = string(r.re.ExpandString(nil, r.Version, ua, m))
+	} else {
+		for i := 2; i < len(m); i += 2 {
+			if m[i] >= 0 && m[i+1] > m[i] {
+				v = ua[m[i]:m[i+1]]
+				break
+			}
+		}
+	}
+	v = strings.Trim(strings.ReplaceAll(v, "_", "."), ". ")
+	if len(v) > maxVersion {
+		v = v[:maxVersion]
+	}
+	return v
+}
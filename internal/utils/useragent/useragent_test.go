@@ -0,0 +1,202 @@
+package useragent
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	p := Default()
+
+	tests := []struct {
+		name string
+		ua   string
+		want Agent
+	}{
+		{
+			"chrome on windows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			Agent{DeviceDesktop, OSWindows, "10", "Chrome", "124"},
+		},
+		{
+			"edge is not chrome",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51",
+			Agent{DeviceDesktop, OSWindows, "10", "Edge", "124"},
+		},
+		{
+			"firefox on linux",
+			"Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			Agent{DeviceDesktop, OSLinux, "", "Firefox", "125"},
+		},
+		{
+			"safari on macos",
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+			Agent{DeviceDesktop, OSMacOS, "10.15", "Safari", "17.4"},
+		},
+		{
+			"iphone",
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4.1 Mobile/15E148 Safari/604.1",
+			Agent{DeviceMobile, OSIOS, "17.4", "Safari", "17.4"},
+		},
+		{
+			"ipad is a tablet",
+			"Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.6 Mobile/15E148 Safari/604.1",
+			Agent{DeviceTablet, OSIOS, "16.6", "Safari", "16.6"},
+		},
+		{
+			"chrome on iphone",
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/124.0.6367.71 Mobile/15E148 Safari/604.1",
+			Agent{DeviceMobile, OSIOS, "17.4", "Chrome", "124"},
+		},
+		{
+			"android phone",
+			"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Mobile Safari/537.36",
+			Agent{DeviceMobile, OSAndroid, "14", "Chrome", "124"},
+		},
+		{
+			"android tablet",
+			"Mozilla/5.0 (Linux; Android 13; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Safari/537.36",
+			Agent{DeviceTablet, OSAndroid, "13", "Chrome", "124"},
+		},
+		{
+			"android webview",
+			"Mozilla/5.0 (Linux; Android 12; SM-G991B; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/123.0.6312.118 Mobile Safari/537.36",
+			Agent{DeviceMobile, OSAndroid, "12", "Android WebView", "123"},
+		},
+		{
+			"samsung internet",
+			"Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/24.0 Chrome/117.0.0.0 Mobile Safari/537.36",
+			Agent{DeviceMobile, OSAndroid, "14", "Samsung Internet", "24"},
+		},
+		{
+			"instagram in-app browser",
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 Instagram 321.0.0.32.111",
+			Agent{DeviceMobile, OSIOS, "17.3", "Instagram", "321"},
+		},
+		{
+			"chromebook",
+			"Mozilla/5.0 (X11; CrOS x86_64 14541.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			Agent{DeviceDesktop, OSChromeOS, "", "Chrome", "124"},
+		},
+		{
+			"googlebot smartphone",
+			"Mozilla/5.0 (Linux; Android 6.0.1; Nexus 5X Build/MMB29P) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.91 Mobile Safari/537.36 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			Agent{DeviceBot, OSAndroid, "6.0", "Googlebot", "2"},
+		},
+		{
+			"link unfurler",
+			"facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)",
+			Agent{DeviceBot, OSOther, "", "Facebook", "1"},
+		},
+		{
+			"telegram preview",
+			"TelegramBot (like TwitterBot)",
+			Agent{DeviceBot, OSOther, "", "TelegramBot", ""},
+		},
+		{
+			"curl",
+			"curl/8.5.0",
+			Agent{DeviceBot, OSOther, "", "curl", "8"},
+		},
+		{
+			"generic crawler",
+			"Mozilla/5.0 (compatible; SomeCrawler/1.0)",
+			Agent{DeviceBot, OSOther, "", "Other", ""},
+		},
+		{
+			"empty",
+			"",
+			Agent{DeviceBot, OSOther, "", BrowserOther, ""},
+		},
+		{
+			"blank",
+			"   ",
+			Agent{DeviceBot, OSOther, "", BrowserOther, ""},
+		},
+		{
+			"unknown",
+			"SomeApp/3.2",
+			Agent{DeviceUnknown, OSOther, "", BrowserOther, ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Parse(tt.ua); got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVersionLength(t *testing.T) {
+	got := Default().Parse("curl/" + strings.Repeat("9", 100))
+	if len(got.BrowserVersion) != maxVersion {
+		t.Fatalf("version is %d bytes long, want %d", len(got.BrowserVersion), maxVersion)
+	}
+}
+
+// Link rules validate OS names against OSes, so a family the built-in rules
+// report but OSes lacks could never be targeted.
+func TestBuiltinOSFamilies(t *testing.T) {
+	var rules Rules
+	if err := json.Unmarshal(builtinRules, &rules); err != nil {
+		t.Fatal(err)
+	}
+	if len(rules.OS) == 0 {
+		t.Fatal("no OS rules")
+	}
+	for _, r := range rules.OS {
+		t.Run(r.Regex, func(t *testing.T) {
+			if !slices.Contains(OSes, r.Family) {
+				t.Fatalf("family %q is not in %v", r.Family, OSes)
+			}
+		})
+	}
+}
+
+func TestNewParserInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{"not json", `{"bots": [`},
+		{"bad regex", `{"browsers": [{"regex": "Chrome/(\\d+", "family": "Chrome"}]}`},
+		{"unknown device in os rule", `{"os": [{"regex": "Windows", "family": "windows", "device": "console"}]}`},
+		{"unknown device in device rule", `{"devices": [{"regex": "Xbox", "device": "console"}]}`},
+		{"missing family", `{"browsers": [{"regex": "Chrome"}]}`},
+		{"missing device", `{"devices": [{"regex": "iPad"}]}`},
+		{"empty regex", `{"bots": [{"regex": "", "family": "Any"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewParser([]byte(tt.rules)); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	rules := `{"browsers": [{"regex": "Via/(\\d+)", "family": "Via"}], "devices": [{"regex": "Watch", "device": "mobile"}]}`
+	if err := os.WriteFile(path, []byte(rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Agent{DeviceMobile, OSOther, "", "Via", "3"}
+	if got := p.Parse("Via/3 (Watch)"); got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,157 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Reasons passed to BatchConfig.Dropped.
+const (
+	DropBufferFull  = "buffer_full"
+	DropWriteFailed = "write_failed"
+	DropStopped     = "stopped"
+)
+
+type BatchConfig struct {
+	// Dropped, if set, is told about items that were given up on.
+	Dropped func(reason string, n int)
+	// Capacity bounds the items buffered in memory.
+	Capacity int
+	// Size is the most items written at once.
+	Size int
+	// Workers is the number of goroutines writing batches.
+	Workers int
+	// Interval is how long a partial batch may wait before it is written.
+	Interval time.Duration
+	// Wait is how long Add blocks on a full buffer before dropping the item.
+	Wait time.Duration
+}
+
+// Batch buffers items in memory and has a pool of goroutines write them out
+// in batches, so producers never wait on the writer itself. When writers
+// fall behind, Add applies backpressure for up to BatchConfig.Wait and then
+// drops the item.
+type Batch[T any] struct {
+	write  func(ctx context.Context, items []T) error
+	log    *slog.Logger
+	cfg    BatchConfig
+	items  chan T
+	mu     sync.RWMutex
+	wg     sync.WaitGroup
+	closed bool
+}
+
+func NewBatch[T any](name string, cfg BatchConfig, write func(ctx context.Context, items []T) error, log *slog.Logger) *Batch[T] {
+	cfg.Capacity = max(cfg.Capacity, 1)
+	cfg.Size = max(cfg.Size, 1)
+	cfg.Workers = max(cfg.Workers, 1)
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+
+	return &Batch[T]{
+		write: write,
+		log:   log.With("op", "worker."+name),
+		cfg:   cfg,
+		items: make(chan T, cfg.Capacity),
+	}
+}
+
+// Start launches the writers.
+func (b *Batch[T]) Start() {
+	b.wg.Add(b.cfg.Workers)
+	for range b.cfg.Workers {
+		go b.run()
+	}
+	b.log.Info("worker started", slog.Int("capacity", b.cfg.Capacity), slog.Int("workers", b.cfg.Workers))
+}
+
+// Add queues an item for writing and reports whether it was accepted.
+func (b *Batch[T]) Add(item T) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		b.dropped(DropStopped, 1)
+		return false
+	}
+
+	select {
+	case b.items <- item:
+		return true
+	default:
+	}
+
+	if b.cfg.Wait > 0 {
+		timer := time.NewTimer(b.cfg.Wait)
+		defer timer.Stop()
+		select {
+		case b.items <- item:
+			return true
+		case <-timer.C:
+		}
+	}
+
+	b.dropped(DropBufferFull, 1)
+	return false
+}
+
+// Stop stops accepting items, writes out everything still buffered and
+// waits for the writers to exit.
+func (b *Batch[T]) Stop() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	close(b.items)
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	b.log.Info("worker stopped")
+}
+
+func (b *Batch[T]) run() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.cfg.Interval)
+	defer ticker.Stop()
+
+	batch := make([]T, 0, b.cfg.Size)
+	for {
+		select {
+		case item, ok := <-b.items:
+			if !ok {
+				b.flush(batch)
+				return
+			}
+			batch = append(batch, item)
+			if len(batch) >= b.cfg.Size {
+				b.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			b.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (b *Batch[T]) flush(batch []T) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := b.write(context.Background(), batch); err != nil {
+		b.log.Error("failed to write batch", "count", len(batch), "error", err)
+		b.dropped(DropWriteFailed, len(batch))
+	}
+}
+
+func (b *Batch[T]) dropped(reason string, n int) {
+	if b.cfg.Dropped != nil {
+		b.cfg.Dropped(reason, n)
+	}
+}
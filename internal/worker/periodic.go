@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Worker is a background job that runs between Start and Stop.
+type Worker interface {
+	Start()
+	Stop()
+}
+
+// Periodic runs a job on a fixed interval in its own goroutine until stopped.
+type Periodic struct {
+	job      func(ctx context.Context) error
+	log      *slog.Logger
+	cancel   context.CancelFunc
+	done     chan struct{}
+	name     string
+	interval time.Duration
+}
+
+func NewPeriodic(name string, interval time.Duration, job func(ctx context.Context) error, log *slog.Logger) *Periodic {
+	return &Periodic{
+		job:      job,
+		log:      log.With("op", "worker."+name),
+		name:     name,
+		interval: interval,
+	}
+}
+
+// Start launches the job loop. The first run happens one interval after start.
+func (p *Periodic) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		p.log.Info("worker started", slog.Duration("interval", p.interval))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.job(ctx); err != nil && ctx.Err() == nil {
+					p.log.Error("job failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop cancels the running job, if any, and waits for the loop to exit.
+func (p *Periodic) Stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+	p.log.Info("worker stopped")
+}